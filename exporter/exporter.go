@@ -0,0 +1,341 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docktermj/go-proc-parse/proc"
+	"github.com/docktermj/go-proc-parse/proc/_pid_/stat"
+)
+
+// Prefix of every metric name.
+const namespace = "proc"
+
+// Content type of the Prometheus text exposition format.
+// References:
+// - https://prometheus.io/docs/instrumenting/exposition_formats/
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Exports the files of a procfs tree as Prometheus metrics.
+// Example:
+//     myFS, err := proc.NewFS("/host/proc")
+//     http.Handle("/metrics", exporter.New(myFS, nil))
+type Exporter struct {
+	fs   proc.FS
+	pids []int
+}
+
+// An Exporter of fs.  Per-process metrics, labeled by pid and comm, are only
+// exported for pids, so that the number of series stays bounded.
+func New(fs proc.FS, pids []int) *Exporter {
+	return &Exporter{
+		fs:   fs,
+		pids: pids,
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Metric families
+// ----------------------------------------------------------------------------
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	labels []label
+	value  float64
+}
+
+type family struct {
+	help    string
+	kind    string
+	samples []sample
+}
+
+type families map[string]*family
+
+func (f families) add(name string, kind string, help string, value float64, labels ...label) {
+	aFamily, ok := f[name]
+	if !ok {
+		aFamily = &family{help: help, kind: kind}
+		f[name] = aFamily
+	}
+	aFamily.samples = append(aFamily.samples, sample{labels: labels, value: value})
+}
+
+func (f families) gauge(name string, help string, value float64, labels ...label) {
+	f.add(name, "gauge", help, value, labels...)
+}
+
+func (f families) counter(name string, help string, value float64, labels ...label) {
+	f.add(name, "counter", help, value, labels...)
+}
+
+// Name of the metric of key, e.g. "proc_net_dev_receive_bytes_total".  Every
+// word of key stays in the name, e.g. "proc_meminfo_mem_total_bytes" for
+// "MemTotal"; only the suffix "total" of counters ends a name in "_total".
+func metricName(subsystem string, key string, suffix string) string {
+	result := namespace + "_" + subsystem + "_" + proc.SnakeCase.Key(key)
+	if suffix != "" {
+		result += "_" + suffix
+	}
+	return result
+}
+
+func escapeLabelValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return strings.ReplaceAll(value, `"`, `\"`)
+}
+
+func escapeHelp(help string) string {
+	help = strings.ReplaceAll(help, `\`, `\\`)
+	return strings.ReplaceAll(help, "\n", `\n`)
+}
+
+// Write families in the Prometheus text exposition format, sorted by name.
+func (f families) write(w io.Writer) error {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	writer := bufio.NewWriter(w)
+	for _, name := range names {
+		aFamily := f[name]
+		fmt.Fprintf(writer, "# HELP %s %s\n", name, escapeHelp(aFamily.help))
+		fmt.Fprintf(writer, "# TYPE %s %s\n", name, aFamily.kind)
+		for _, aSample := range aFamily.samples {
+			writer.WriteString(name)
+			if len(aSample.labels) > 0 {
+				pairs := make([]string, len(aSample.labels))
+				for index, aLabel := range aSample.labels {
+					pairs[index] = aLabel.name + `="` + escapeLabelValue(aLabel.value) + `"`
+				}
+				writer.WriteString("{" + strings.Join(pairs, ",") + "}")
+			}
+			writer.WriteString(" " + strconv.FormatFloat(aSample.value, 'g', -1, 64) + "\n")
+		}
+	}
+	return writer.Flush()
+}
+
+// ----------------------------------------------------------------------------
+// Collectors
+// ----------------------------------------------------------------------------
+
+func (e *Exporter) collectMeminfo(metrics families) error {
+	contents, err := e.fs.Meminfo()
+	if err != nil {
+		return err
+	}
+	for key := range contents.Units {
+		help := "Value of " + key + " from /proc/meminfo."
+		if value, ok := contents.Bytes(key); ok {
+			metrics.gauge(metricName("meminfo", key, "bytes"), help, float64(value))
+			continue
+		}
+
+		// The counts, such as HugePages_Total, are of huge pages.
+
+		if value, ok := contents.Value(key); ok && contents.IsCount(key) {
+			metrics.gauge(metricName("meminfo", key, "pages"), help, float64(value))
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) collectNetDev(metrics families) error {
+	contents, err := e.fs.NetDev()
+	if err != nil {
+		return err
+	}
+	devices := make([]string, 0, len(contents))
+	for device := range contents {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices) // Samples are written in the order they are added.
+
+	for _, device := range devices {
+		aDev := contents[device]
+		deviceLabel := label{"device", device}
+		for key, value := range map[string]uint64{
+			"ReceiveBytes":       aDev.ReceiveBytes,
+			"ReceivePackets":     aDev.ReceivePackets,
+			"ReceiveErrs":        aDev.ReceiveErrs,
+			"ReceiveDrop":        aDev.ReceiveDrop,
+			"ReceiveFifo":        aDev.ReceiveFifo,
+			"ReceiveFrame":       aDev.ReceiveFrame,
+			"ReceiveCompressed":  aDev.ReceiveCompressed,
+			"ReceiveMulticast":   aDev.ReceiveMulticast,
+			"TransmitBytes":      aDev.TransmitBytes,
+			"TransmitPackets":    aDev.TransmitPackets,
+			"TransmitErrs":       aDev.TransmitErrs,
+			"TransmitDrop":       aDev.TransmitDrop,
+			"TransmitFifo":       aDev.TransmitFifo,
+			"TransmitColls":      aDev.TransmitColls,
+			"TransmitCarrier":    aDev.TransmitCarrier,
+			"TransmitCompressed": aDev.TransmitCompressed,
+		} {
+			help := "Value of " + key + " from /proc/net/dev."
+			metrics.counter(metricName("net_dev", key, "total"), help, float64(value), deviceLabel)
+		}
+	}
+	return nil
+}
+
+// Fields of /proc/net/snmp that are not counters.
+var snmpGauges = map[string]bool{
+	"Forwarding":   true,
+	"DefaultTTL":   true,
+	"RtoAlgorithm": true,
+	"RtoMin":       true,
+	"RtoMax":       true,
+	"MaxConn":      true,
+	"CurrEstab":    true,
+}
+
+// Values of the fields of a section of snmp.Snmp, keyed by their json tags,
+// which name them as the kernel does.  Fields are uint64 but for signed ones
+// such as Tcp MaxConn, and IcmpMsg is a map.
+func snmpFields(section interface{}) map[string]float64 {
+	result := make(map[string]float64)
+	value := reflect.ValueOf(section)
+	if value.Kind() == reflect.Map {
+		iterator := value.MapRange()
+		for iterator.Next() {
+			result[iterator.Key().String()] = float64(iterator.Value().Uint())
+		}
+		return result
+	}
+	for index := 0; index < value.NumField(); index++ {
+		key, _, _ := strings.Cut(value.Type().Field(index).Tag.Get("json"), ",")
+		switch field := value.Field(index); field.Kind() {
+		case reflect.Int64:
+			result[key] = float64(field.Int())
+		case reflect.Uint64:
+			result[key] = float64(field.Uint())
+		}
+	}
+	return result
+}
+
+func (e *Exporter) collectNetSnmp(metrics families) error {
+	contents, err := e.fs.NetSnmp()
+	if err != nil {
+		return err
+	}
+	for _, section := range []struct {
+		protocol string
+		fields   interface{}
+	}{
+		{"Icmp", contents.Icmp},
+		{"IcmpMsg", contents.IcmpMsg},
+		{"Ip", contents.Ip},
+		{"Tcp", contents.Tcp},
+		{"Udp", contents.Udp},
+		{"UdpLite", contents.UdpLite},
+	} {
+		protocolLabel := label{"protocol", section.protocol}
+		for key, value := range snmpFields(section.fields) {
+			help := "Value of " + key + " from /proc/net/snmp."
+			if snmpGauges[key] {
+				metrics.gauge(metricName("net_snmp", key, ""), help, value, protocolLabel)
+				continue
+			}
+			metrics.counter(metricName("net_snmp", key, "total"), help, value, protocolLabel)
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) stats() ([]stat.Stat, error) {
+	result := []stat.Stat{}
+	for _, pid := range e.pids {
+		aStat, err := e.fs.Stat(pid)
+		if proc.IsGone(err) {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result = append(result, aStat)
+	}
+	return result, nil
+}
+
+func (e *Exporter) collectPidStat(metrics families) error {
+	stats, err := e.stats()
+	if err != nil {
+		return err
+	}
+
+	// Start times are relative to boot.  Leave them out if it is unknown.
+
+	systemStat, err := e.fs.SystemStat()
+	btime := systemStat.Btime
+	if err != nil {
+		btime = 0
+	}
+
+	for _, aStat := range stats {
+		pidLabel := label{"pid", strconv.Itoa(aStat.Pid)}
+		commLabel := label{"comm", aStat.Comm}
+		cpuHelp := "CPU time from utime and stime of /proc/[pid]/stat."
+		metrics.counter(metricName("pid_stat", "cpu_seconds", "total"), cpuHelp, aStat.UserTime().Seconds(), pidLabel, commLabel, label{"mode", "user"})
+		metrics.counter(metricName("pid_stat", "cpu_seconds", "total"), cpuHelp, aStat.SystemTime().Seconds(), pidLabel, commLabel, label{"mode", "system"})
+		if btime != 0 {
+			metrics.gauge(metricName("pid_stat", "start_time", "seconds"), "Start time from starttime of /proc/[pid]/stat, in seconds since the epoch.", float64(aStat.StartTime(btime).UnixMilli())/1000, pidLabel, commLabel)
+		}
+		metrics.counter(metricName("pid_stat", "minor_faults", "total"), "Value of minflt from /proc/[pid]/stat.", float64(aStat.Minflt), pidLabel, commLabel)
+		metrics.counter(metricName("pid_stat", "major_faults", "total"), "Value of majflt from /proc/[pid]/stat.", float64(aStat.Majflt), pidLabel, commLabel)
+		metrics.gauge(metricName("pid_stat", "num_threads", ""), "Value of num_threads from /proc/[pid]/stat.", float64(aStat.Num_threads), pidLabel, commLabel)
+		metrics.gauge(metricName("pid_stat", "virtual_memory", "bytes"), "Value of vsize from /proc/[pid]/stat.", float64(aStat.Vsize), pidLabel, commLabel)
+		metrics.gauge(metricName("pid_stat", "resident_memory", "bytes"), "Resident set size from rss of /proc/[pid]/stat.", float64(aStat.RSSBytes()), pidLabel, commLabel)
+	}
+	return nil
+}
+
+// Adds the metrics of a file.
+type collector struct {
+	file    string
+	collect func(families) error
+}
+
+// Write all metrics in the Prometheus text exposition format.  A file that
+// cannot be read is reported by proc_scrape_success rather than failing the
+// whole scrape.
+func (e *Exporter) Write(w io.Writer) error {
+	metrics := families{}
+	collectors := []collector{
+		{"meminfo", e.collectMeminfo},
+		{"net/dev", e.collectNetDev},
+		{"net/snmp", e.collectNetSnmp},
+	}
+	if len(e.pids) > 0 {
+		collectors = append(collectors, collector{"[pid]/stat", e.collectPidStat})
+	}
+	for _, collector := range collectors {
+		success := 1.0
+		if err := collector.collect(metrics); err != nil {
+			success = 0
+		}
+		metrics.gauge(namespace+"_scrape_success", "Whether the file could be read and parsed.", success, label{"file", collector.file})
+	}
+	return metrics.write(w)
+}
+
+// ServeHTTP implements http.Handler.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	e.Write(w)
+}
@@ -0,0 +1,140 @@
+package exporter
+
+import (
+	"bufio"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/docktermj/go-proc-parse/proc"
+)
+
+// A procfs tree under testdata, with the process 1.
+const testTree = "../testdata/linux-6.18-container-x86_64"
+
+// A sample of a scrape: the labels of a metric, sorted by name, and its
+// value.
+type scraped struct {
+	labels string
+	value  float64
+}
+
+// Scrape testTree and return the type and the samples of each metric.
+func scrape(t *testing.T) (map[string]string, map[string][]scraped) {
+	t.Helper()
+	myFS, err := proc.NewFS(testTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	New(myFS, []int{1}).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if contentType := recorder.Header().Get("Content-Type"); contentType != ContentType {
+		t.Errorf("Content-Type %q; want %q", contentType, ContentType)
+	}
+
+	types := map[string]string{}
+	samples := map[string][]scraped{}
+	scanner := bufio.NewScanner(recorder.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if fields := strings.Fields(line); strings.HasPrefix(line, "# TYPE ") && len(fields) == 4 {
+			types[fields[2]] = fields[3]
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		series, text, ok := strings.Cut(line, " ")
+		if !ok {
+			t.Fatalf("no value: %q", line)
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		name, labels, _ := strings.Cut(strings.TrimSuffix(series, "}"), "{")
+		pairs := strings.Split(labels, ",")
+		sort.Strings(pairs)
+		samples[name] = append(samples[name], scraped{labels: strings.Join(pairs, ","), value: value})
+	}
+	return types, samples
+}
+
+func TestScrape(t *testing.T) {
+	types, samples := scrape(t)
+
+	// Every metric has a type, and only counters end in "_total".
+
+	for name := range samples {
+		kind, ok := types[name]
+		if !ok {
+			t.Errorf("%s has no TYPE", name)
+		}
+		if isCounter := kind == "counter"; isCounter != strings.HasSuffix(name, "_total") {
+			t.Errorf("%s is a %s", name, kind)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		kind   string
+		labels string
+		value  float64
+	}{
+		{"proc_meminfo_mem_total_bytes", "gauge", "", 6147400 * 1024},
+		{"proc_meminfo_huge_pages_total_pages", "gauge", "", 0},
+		{"proc_net_dev_receive_bytes_total", "counter", `device="lo"`, 55664470},
+		{"proc_net_dev_receive_packets_total", "counter", `device="eth0"`, 57},
+		{"proc_net_snmp_max_conn", "gauge", `protocol="Tcp"`, -1},
+		{"proc_net_snmp_rto_min", "gauge", `protocol="Tcp"`, 200},
+		{"proc_net_snmp_active_opens_total", "counter", `protocol="Tcp"`, 31},
+		{"proc_pid_stat_minor_faults_total", "counter", `comm="process_api",pid="1"`, 56199},
+		{"proc_scrape_success", "gauge", `file="meminfo"`, 1},
+		{"proc_scrape_success", "gauge", `file="net/dev"`, 1},
+		{"proc_scrape_success", "gauge", `file="net/snmp"`, 1},
+		{"proc_scrape_success", "gauge", `file="[pid]/stat"`, 1},
+	}
+	for _, test := range tests {
+		if kind := types[test.name]; kind != test.kind {
+			t.Errorf("%s is a %q; want %q", test.name, kind, test.kind)
+		}
+		found := false
+		for _, aSample := range samples[test.name] {
+			if aSample.labels == test.labels {
+				found = true
+				if aSample.value != test.value {
+					t.Errorf("%s{%s} = %g; want %g", test.name, test.labels, aSample.value, test.value)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("no %s{%s}", test.name, test.labels)
+		}
+	}
+
+	// The label sets of families that are labeled.
+
+	labelNames := map[string]string{
+		"proc_net_dev_transmit_bytes_total": "device",
+		"proc_net_snmp_in_receives_total":   "protocol",
+		"proc_pid_stat_cpu_seconds_total":   "comm,mode,pid",
+		"proc_pid_stat_start_time_seconds":  "comm,pid",
+	}
+	for name, expected := range labelNames {
+		if len(samples[name]) == 0 {
+			t.Errorf("no %s", name)
+		}
+		for _, aSample := range samples[name] {
+			names := []string{}
+			for _, pair := range strings.Split(aSample.labels, ",") {
+				labelName, _, _ := strings.Cut(pair, "=")
+				names = append(names, labelName)
+			}
+			if actual := strings.Join(names, ","); actual != expected {
+				t.Errorf("%s has the labels %s; want %s", name, actual, expected)
+			}
+		}
+	}
+}
@@ -1,13 +1,39 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/docktermj/go-proc-parse/exporter"
+	"github.com/docktermj/go-proc-parse/proc"
+	"github.com/docktermj/go-proc-parse/proc/_pid_/fd"
 	"github.com/docktermj/go-proc-parse/proc/_pid_/stat"
+	"github.com/docktermj/go-proc-parse/proc/_pid_/status"
+	"github.com/docktermj/go-proc-parse/proc/loadavg"
 	"github.com/docktermj/go-proc-parse/proc/meminfo"
 	"github.com/docktermj/go-proc-parse/proc/net/dev"
+	"github.com/docktermj/go-proc-parse/proc/net/netstat"
 	"github.com/docktermj/go-proc-parse/proc/net/snmp"
+	"github.com/docktermj/go-proc-parse/proc/net/tcp"
+	sysstat "github.com/docktermj/go-proc-parse/proc/stat"
+	"github.com/docktermj/go-proc-parse/proc/uptime"
 )
 
 // Values updated via "go install -ldflags" parameters.
@@ -20,8 +46,20 @@ func displayBanner(title string) {
 	fmt.Printf("\n---------- %s ------------------------------\n\n", title)
 }
 
+func demoProcPidFd() {
+	pid := os.Getpid()
+	displayBanner("/proc/" + strconv.Itoa(pid) + "/fd")
+	contents, _ := fd.Get(pid)
+	contentsAsJson, _ := fd.GetAsJson(pid)
+	contentsAsMap, _ := fd.GetAsMap(pid)
+	fmt.Printf("%+v\n\n", contents)
+	fmt.Printf("%s\n\n", contentsAsJson)
+	fmt.Printf("%+v\n\n", contentsAsMap)
+	fmt.Printf("Standard output:  %s\n", contentsAsMap["1"])
+}
+
 func demoProcPidStat() {
-	pid := int(5)
+	pid := os.Getpid()
 	displayBanner("/proc/" + strconv.Itoa(pid) + "/stat")
 	contents, _ := stat.Get(pid)
 	contentsAsJson, _ := stat.GetAsJson(pid)
@@ -30,6 +68,43 @@ func demoProcPidStat() {
 	fmt.Printf("%s\n\n", contentsAsJson)
 	fmt.Printf("%+v\n\n", contentsAsMap)
 	fmt.Printf("Stat:  %d\n", contents.Blocked)
+	systemStat, _ := sysstat.Get()
+	fmt.Printf("Started at %s, used %s CPU, %d MiB RSS\n",
+		contents.StartTime(systemStat.Btime).Format("15:04"),
+		contents.CPUTime(),
+		contents.RSSBytes()/(1024*1024))
+}
+
+func demoProcPidStatus() {
+	pid := os.Getpid()
+	displayBanner("/proc/" + strconv.Itoa(pid) + "/status")
+	contents, _ := status.Get(pid)
+	contentsAsJson, _ := status.GetAsJson(pid)
+	contentsAsMap, _ := status.GetAsMap(pid)
+	fmt.Printf("%+v\n\n", contents)
+	fmt.Printf("%s\n\n", contentsAsJson)
+	fmt.Printf("%+v\n\n", contentsAsMap)
+	fmt.Printf("Effective capabilities:  %s\n", contents.CapEff)
+}
+
+func demoProcAll() {
+	displayBanner("/proc/[pid]/stat for all processes")
+	contents, _ := proc.AllProcs()
+	for _, content := range contents {
+		fmt.Printf("%8d  %s\n", content.Pid, content.Comm)
+	}
+	fmt.Printf("\nProcesses:  %d\n", len(contents))
+}
+
+func demoProcLoadavg() {
+	displayBanner("/proc/loadavg")
+	contents, _ := loadavg.Get()
+	contentsAsJson, _ := loadavg.GetAsJson()
+	contentsAsMap, _ := loadavg.GetAsMap()
+	fmt.Printf("%+v\n\n", contents)
+	fmt.Printf("%s\n\n", contentsAsJson)
+	fmt.Printf("%+v\n\n", contentsAsMap)
+	fmt.Printf("\nLoad average (1 minute):  %.2f\n", contents.Load1)
 }
 
 func demoProcMeminfo() {
@@ -40,7 +115,13 @@ func demoProcMeminfo() {
 	fmt.Printf("%+v\n\n", contents)
 	fmt.Printf("%s\n\n", contentsAsJson)
 	fmt.Printf("%+v\n\n", contentsAsMap)
-	fmt.Printf("\nMemory Available:  %d\n", contents.MemAvailable)
+	fmt.Printf("\nMemory Available:  %d %s\n", contents.MemAvailable, contents.Unit("MemAvailable"))
+	memTotal, _ := contents.Bytes("MemTotal")
+	fmt.Printf("Memory Total:  %d bytes\n", memTotal)
+	summary, _ := meminfo.GetSummary()
+	fmt.Printf("\n%14s %12s %12s %12s %12s %12s\n", "total", "used", "free", "shared", "buff/cache", "available")
+	fmt.Printf("Mem: %9d %12d %12d %12d %12d %12d\n", summary.Total/1024, summary.Used/1024, summary.Free/1024, summary.Shared/1024, (summary.Buffers+summary.Cache)/1024, summary.Available/1024)
+	fmt.Printf("Swap: %8d %12d %12d\n", summary.SwapTotal/1024, summary.SwapUsed/1024, summary.SwapFree/1024)
 }
 
 func demoProcNetDev() {
@@ -51,21 +132,659 @@ func demoProcNetDev() {
 	fmt.Printf("%+v\n\n", contents)
 	fmt.Printf("%s\n\n", contentsAsJson)
 	fmt.Printf("%+v\n\n", contentsAsMap)
-	fmt.Printf("\nReceived bytes:  %d\n", contentsAsMap["lo"]["receive-bytes"])
+	fmt.Printf("\nReceived bytes:  %d\n", contentsAsMap["lo"]["ReceiveBytes"])
+}
+
+func demoProcNetNetstat() {
+	displayBanner("/proc/net/netstat")
+	contents, _ := netstat.Get()
+	contentsAsJson, _ := netstat.GetAsJson()
+	contentsAsMap, _ := netstat.GetAsMap()
+	fmt.Printf("%+v\n\n", contents)
+	fmt.Printf("%s\n\n", contentsAsJson)
+	fmt.Printf("%+v\n\n", contentsAsMap)
+	fmt.Printf("\nListen overflows:  %d\n", contents.TcpExt.ListenOverflows)
 }
 
 func demoProcNetSnmp() {
 	displayBanner("/proc/net/snmp")
+	contents, _ := snmp.Get()
 	contentsAsJson, _ := snmp.GetAsJson()
 	contentsAsMap, _ := snmp.GetAsMap()
+	fmt.Printf("%+v\n\n", contents)
 	fmt.Printf("%s\n\n", contentsAsJson)
 	fmt.Printf("%+v\n\n", contentsAsMap)
-	fmt.Printf("\nOutbound datagrams:  %d\n", contentsAsMap["Udp"]["OutDatagrams"])
+	fmt.Printf("\nOutbound datagrams:  %d\n", contents.Udp.OutDatagrams)
+	fmt.Printf("Maximum TCP connections:  %d\n", contents.Tcp.MaxConn)
 }
 
-func main() {
+func demoProcNetTcp() {
+	displayBanner("/proc/net/tcp")
+	contents, _ := tcp.Get(tcp.Tcp)
+	contentsAsJson, _ := tcp.GetAsJson(tcp.Tcp)
+	contentsAsMap, _ := tcp.GetAsMap(tcp.Tcp)
+	fmt.Printf("%+v\n\n", contents)
+	fmt.Printf("%s\n\n", contentsAsJson)
+	fmt.Printf("%+v\n\n", contentsAsMap)
+	index, _ := fd.GetIndex()
+	for _, socket := range contents {
+		if socket.St == tcp.Listen {
+			fmt.Printf("Listening:  %s  (inode %d)", socket.Local_address, socket.Inode)
+			for _, owner := range index.Sockets[socket.Inode] {
+				fmt.Printf("  %s[%d]", owner.Comm, owner.Pid)
+			}
+			fmt.Printf("\n")
+		}
+	}
+	if len(index.Skipped) > 0 {
+		fmt.Printf("Processes not readable:  %d\n", len(index.Skipped))
+	}
+}
+
+func demoProcStat() {
+	displayBanner("/proc/stat")
+	contents, _ := sysstat.Get()
+	contentsAsJson, _ := sysstat.GetAsJson()
+	contentsAsMap, _ := sysstat.GetAsMap()
+	fmt.Printf("%+v\n\n", contents)
+	fmt.Printf("%s\n\n", contentsAsJson)
+	fmt.Printf("%+v\n\n", contentsAsMap)
+	fmt.Printf("\nContext switches:  %d\n", contents.Ctxt)
+}
+
+func demoProcUptime() {
+	displayBanner("/proc/uptime")
+	contents, _ := uptime.Get()
+	contentsAsJson, _ := uptime.GetAsJson()
+	contentsAsMap, _ := uptime.GetAsMap()
+	fmt.Printf("%+v\n\n", contents)
+	fmt.Printf("%s\n\n", contentsAsJson)
+	fmt.Printf("%+v\n\n", contentsAsMap)
+	fmt.Printf("\nUp:  %s\n", contents.Uptime)
+}
+
+// Parse a comma-separated list of process IDs.
+func parsePids(value string) ([]int, error) {
+	result := []int{}
+	for _, split := range strings.Split(value, ",") {
+		if split == "" {
+			continue
+		}
+		pid, err := strconv.Atoi(split)
+		if err != nil {
+			return result, fmt.Errorf("invalid pid %q", split)
+		}
+		result = append(result, pid)
+	}
+	return result, nil
+}
+
+// The procfs tree at mountPoint, or the default one, which honors the OS
+// Environment variable overrides.
+func procFSAt(mountPoint string) proc.FS {
+	if mountPoint == proc.DefaultMountPoint {
+		return proc.Default()
+	}
+	result, err := proc.NewFS(mountPoint)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return result
+}
+
+// Serve Prometheus metrics.
+// Example:
+//     go-proc-parse serve --listen :9101 --procfs /host/proc --pids 1,2
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := flags.String("listen", ":9101", "address to serve /metrics on")
+	mountPoint := flags.String("procfs", proc.DefaultMountPoint, "mount point of the procfs tree to export")
+	pidList := flags.String("pids", "", "comma-separated process IDs to export per-process metrics for (default none)")
+	flags.Parse(args)
+
+	pids, err := parsePids(*pidList)
+	if err != nil {
+		log.Fatal(err)
+	}
+	procFS := procFSAt(*mountPoint)
+
+	http.Handle("/metrics", exporter.New(procFS, pids))
+	log.Printf("%s %s-%s serving metrics on %s/metrics", programName, buildVersion, buildIteration, *listen)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
+
+// Print the processes using the most CPU at every interval.
+// Example:
+//     go-proc-parse top --interval 2s --count 10
+func top(args []string) {
+	flags := flag.NewFlagSet("top", flag.ExitOnError)
+	interval := flags.Duration("interval", 2*time.Second, "time between samples")
+	count := flags.Int("count", 10, "number of processes to print")
+	pidList := flags.String("pids", "", "comma-separated process IDs to sample (default all)")
+	flags.Parse(args)
+
+	pids, err := parsePids(*pidList)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sampler := proc.NewSampler(proc.Default(), pids)
+	err = sampler.Run(context.Background(), *interval, func(rates proc.Rates) {
+		sorted := make([]proc.Rate, 0, len(rates))
+		for _, rate := range rates {
+			sorted = append(sorted, rate)
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].CPUPercent > sorted[j].CPUPercent })
+		if len(sorted) > *count {
+			sorted = sorted[:*count]
+		}
+		fmt.Printf("\n%8s  %6s  %10s  %10s  %12s  %s\n", "PID", "%CPU", "MINFLT/s", "MAJFLT/s", "RSS DELTA", "COMMAND")
+		for _, rate := range sorted {
+			fmt.Printf("%8d  %6.1f  %10.1f  %10.1f  %12d  %s\n", rate.Pid, rate.CPUPercent, rate.Minflt, rate.Majflt, rate.RSSDelta, rate.Comm)
+		}
+	})
+	log.Fatal(err)
+}
+
+// Print the JSON of a file by the name of its schema, e.g. "net_dev".  With
+// --strict, a value that cannot be parsed is an error.
+// Example:
+//     PROC_MEMINFO=testdata/synthetic-linux-3.10-centos7-x86_64/meminfo go-proc-parse json --strict meminfo
+func printJson(args []string) {
+	flags := flag.NewFlagSet("json", flag.ExitOnError)
+	keyCase := flags.String("keys", "kernel", "case of keys: kernel, snake or camel")
+	mountPoint := flags.String("procfs", proc.DefaultMountPoint, "mount point of the procfs tree to read")
+	pid := flags.Int("pid", 1, "process ID for files of a process, e.g. pid_stat")
+	strict := flags.Bool("strict", false, "fail on values that cannot be parsed")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s json [options] <name>\nNames: %s\n", programName, strings.Join(proc.SchemaNames(), ", "))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	keys, err := proc.ParseKeyCase(*keyCase)
+	if err != nil {
+		log.Fatal(err)
+	}
+	procFS := procFSAt(*mountPoint)
+	if *strict {
+		procFS = procFS.Strict()
+	}
+
+	content, err := procFS.Get(flags.Arg(0), *pid)
+	if err != nil {
+		log.Fatal(err)
+	}
+	compact, err := proc.MarshalJSON(content, keys)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printIndented(compact)
+}
+
+// Print JSON indented by two spaces.
+func printIndented(compact []byte) {
+	indented := bytes.Buffer{}
+	if err := json.Indent(&indented, compact, "", "  "); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(indented.String())
+}
+
+// Create the archive path for record, compressed as its extension says.
+func createArchive(path string) (io.WriteCloser, error) {
+	switch {
+	case strings.HasSuffix(path, ".zst"):
+		command := exec.Command("zstd", "--quiet", "--force", "-o", path)
+		command.Stderr = os.Stderr
+		stdin, err := command.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := command.Start(); err != nil {
+			return nil, fmt.Errorf("%s needs the zstd command: %w", path, err)
+		}
+		return archiveWriter{stdin, func() error {
+			stdin.Close()
+			return command.Wait()
+		}}, nil
+	case strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".tgz"):
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		gzipWriter := gzip.NewWriter(file)
+		return archiveWriter{gzipWriter, func() error {
+			return errors.Join(gzipWriter.Close(), file.Close())
+		}}, nil
+	}
+	return os.Create(path)
+}
+
+// A writer that runs close when it is closed.
+type archiveWriter struct {
+	io.Writer
+	close func() error
+}
+
+func (w archiveWriter) Close() error {
+	return w.close()
+}
+
+// Open the archive path for replay.  NewRecordingReader undoes gzip itself; zstd
+// needs the zstd command.
+func openArchive(path string) (io.ReadCloser, error) {
+	if !strings.HasSuffix(path, ".zst") {
+		return os.Open(path)
+	}
+	command := exec.Command("zstd", "--quiet", "--decompress", "--stdout", path)
+	command.Stderr = os.Stderr
+	stdout, err := command.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := command.Start(); err != nil {
+		return nil, fmt.Errorf("%s needs the zstd command: %w", path, err)
+	}
+	return archiveReader{stdout, command}, nil
+}
+
+// The output of a decompressing command, which is read to the end and waited
+// for when closed.
+type archiveReader struct {
+	io.Reader
+	command *exec.Cmd
+}
+
+func (r archiveReader) Close() error {
+	io.Copy(io.Discard, r.Reader) // The command cannot exit while its output is not read.
+	return r.command.Wait()
+}
+
+// Write a snapshot of the procfs tree every --interval to --out, until
+// --count snapshots are written or the program is interrupted.  An --out
+// ending in .gz or .tgz is compressed with gzip, one ending in .zst with the
+// zstd command.
+// Example:
+//     go-proc-parse record --interval 5s --out capture.tar.zst
+func record(args []string) {
+	flags := flag.NewFlagSet("record", flag.ExitOnError)
+	interval := flags.Duration("interval", 5*time.Second, "time between snapshots")
+	out := flags.String("out", "capture.tar.zst", "archive to write: .tar, .tar.gz, .tgz or .tar.zst")
+	count := flags.Int("count", 0, "number of snapshots to write, or 0 to write until interrupted")
+	mountPoint := flags.String("procfs", proc.DefaultMountPoint, "mount point of the procfs tree to record")
+	flags.Parse(args)
+
+	procFS := procFSAt(*mountPoint)
+	output, err := createArchive(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := proc.NewRecorder(output, procFS)
+
+	// Stop between snapshots on SIGINT or SIGTERM, so that the archive is
+	// complete.
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	written := 0
+	for *count == 0 || written < *count {
+		if err := recorder.Snapshot(time.Now()); err != nil {
+			log.Fatal(err)
+		}
+		written++
+		if written == *count {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+			continue
+		}
+		break
+	}
+
+	if err := errors.Join(recorder.Close(), output.Close()); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d snapshots to %s", written, *out)
+}
+
+// Print the JSON of a file by the name of its schema, e.g. "net_dev", in
+// every snapshot of a recording written by record, as an array of
+// {"time": ..., "value": ...}.  A snapshot where the file cannot be read, e.g.
+// because the process had exited, has an "error" instead of a "value".
+// Example:
+//     go-proc-parse replay --in capture.tar.zst --keys snake meminfo
+func replay(args []string) {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	in := flags.String("in", "capture.tar.zst", "archive written by record")
+	keyCase := flags.String("keys", "kernel", "case of keys: kernel, snake or camel")
+	pid := flags.Int("pid", 1, "process ID for files of a process, e.g. pid_stat")
+	strict := flags.Bool("strict", false, "fail on values that cannot be parsed")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s replay [options] <name>\nNames: %s\n", programName, strings.Join(proc.SchemaNames(), ", "))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	keys, err := proc.ParseKeyCase(*keyCase)
+	if err != nil {
+		log.Fatal(err)
+	}
+	input, err := openArchive(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recording, err := proc.NewRecordingReader(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	type entry struct {
+		Time  time.Time       `json:"time"`
+		Value json.RawMessage `json:"value,omitempty"`
+		Error string          `json:"error,omitempty"`
+	}
+	entries := []entry{}
+	for {
+		snapshot, err := recording.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		procFS := snapshot.FS
+		if *strict {
+			procFS = procFS.Strict()
+		}
+		anEntry := entry{Time: snapshot.Time}
+		content, err := procFS.Get(flags.Arg(0), *pid)
+		if err == nil {
+			anEntry.Value, err = proc.MarshalJSON(content, keys)
+		}
+		if err != nil {
+			anEntry.Error = err.Error()
+		}
+		entries = append(entries, anEntry)
+	}
+	if err := input.Close(); err != nil {
+		log.Fatal(err)
+	}
+	compact, err := json.Marshal(entries)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printIndented(compact)
+}
+
+// The snapshot at index of the recording read from r, counting from 0, or
+// back from the last if negative, or nil and the number of snapshots if
+// there is none.  Only the snapshots that may be the one at index are kept.
+func snapshotAt(r io.Reader, index int) (*proc.Snapshot, int, error) {
+	recording, err := proc.NewRecordingReader(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	last := []proc.Snapshot{}
+	count := 0
+	for {
+		snapshot, err := recording.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, count, err
+		}
+		if count == index {
+			return &snapshot, count + 1, nil
+		}
+		count++
+		if index < 0 {
+			last = append(last, snapshot)
+			if len(last) > -index {
+				last = last[1:]
+			}
+		}
+	}
+	if index >= 0 || len(last) < -index {
+		return nil, count, nil
+	}
+	return &last[0], count, nil
+}
+
+// The procfs tree named by an argument of diff: "live" for a snapshot of the
+// default tree taken now, an archive written by record, or a directory
+// holding a procfs tree.  An archive is read at its first snapshot, or at the
+// one after "@", counting from 0, or back from the last if negative.
+func diffTree(arg string) (proc.FS, error) {
+	if arg == "live" {
+		buffer := bytes.Buffer{}
+		recorder := proc.NewRecorder(&buffer, proc.Default())
+		if err := errors.Join(recorder.Snapshot(time.Now()), recorder.Close()); err != nil {
+			return proc.FS{}, err
+		}
+		snapshot, _, err := snapshotAt(&buffer, 0)
+		if err != nil {
+			return proc.FS{}, err
+		}
+		return snapshot.FS, nil
+	}
+
+	path, index := arg, 0
+	if at := strings.LastIndex(arg, "@"); at >= 0 {
+		if parsed, err := strconv.Atoi(arg[at+1:]); err == nil {
+			path, index = arg[:at], parsed
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return proc.FS{}, err
+	}
+	if info.IsDir() {
+		return procFSAt(path), nil
+	}
+
+	input, err := openArchive(path)
+	if err != nil {
+		return proc.FS{}, err
+	}
+	snapshot, count, err := snapshotAt(input, index)
+	if err := errors.Join(err, input.Close()); err != nil {
+		return proc.FS{}, err
+	}
+	if snapshot == nil {
+		return proc.FS{}, fmt.Errorf("%s has %d snapshots, not one at %s", path, count, arg[len(path):])
+	}
+	return snapshot.FS, nil
+}
+
+// A value of a proc.Change for the table of diff.
+func formatValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		return value
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case uint64:
+		return strconv.FormatUint(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(content)
+}
+
+// Print what changed between two procfs trees: the values of every file
+// that differ, as returned by proc.Compare, and the processes that appeared
+// or exited.  Each tree is "live", an archive written by record, optionally
+// with "@" and the index of a snapshot, or a directory.  Comparing "live" with
+// "live" waits --interval between the snapshots.
+// Example:
+//     go-proc-parse diff capture.tar.zst@0 capture.tar.zst@-1
+//     go-proc-parse diff --json --files net_snmp,net_dev live live
+func diff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	fileList := flags.String("files", "", "comma-separated names of the files to compare (default all)")
+	interval := flags.Duration("interval", time.Second, "time between the snapshots when comparing live with live")
+	asJson := flags.Bool("json", false, "print JSON instead of a table")
+	strict := flags.Bool("strict", false, "fail on values that cannot be parsed")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s diff [options] <before> <after>\nNames: %s\n", programName, strings.Join(proc.DiffNames(), ", "))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	names := []string{}
+	if *fileList != "" {
+		names = strings.Split(*fileList, ",")
+	}
+	before, err := diffTree(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if flags.Arg(0) == "live" && flags.Arg(1) == "live" {
+		time.Sleep(*interval)
+	}
+	after, err := diffTree(flags.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *strict {
+		before, after = before.Strict(), after.Strict()
+	}
+
+	result, err := proc.Compare(before, after, names...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *asJson {
+		compact, err := json.Marshal(result)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printIndented(compact)
+		return
+	}
+
+	for _, name := range result.Skipped {
+		log.Printf("%s cannot be read in both trees; skipped", name)
+	}
+	if len(result.Appeared)+len(result.Exited) > 0 {
+		fmt.Printf("%-8s  %8s  %s\n", "PROCESS", "PID", "COMMAND")
+		for _, process := range result.Exited {
+			fmt.Printf("%-8s  %8d  %s\n", "exited", process.Pid, process.Comm)
+		}
+		for _, process := range result.Appeared {
+			fmt.Printf("%-8s  %8d  %s\n", "appeared", process.Pid, process.Comm)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%-12s  %8s  %-40s  %20s  %20s  %12s\n", "FILE", "PID", "KEY", "OLD", "NEW", "DELTA")
+	for _, change := range result.Changes {
+		pid, delta := "", ""
+		if change.Pid != 0 {
+			pid = strconv.Itoa(change.Pid)
+		}
+		switch value := change.Delta.(type) {
+		case int64:
+			delta = fmt.Sprintf("%+d", value)
+		case float64:
+			delta = fmt.Sprintf("%+.6g", value)
+		}
+		fmt.Printf("%-12s  %8s  %-40s  %20s  %20s  %12s\n", change.File, pid, change.Key, formatValue(change.Old), formatValue(change.New), delta)
+	}
+}
+
+// Write the JSON Schema of every package, for keys in every case.
+// Example:
+//     go-proc-parse schema --dir schema
+func schema(args []string) {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	dir := flags.String("dir", "schema", "directory to write v<version>/<case>/<name>.schema.json under")
+	flags.Parse(args)
+
+	for _, keys := range []proc.KeyCase{proc.KernelCase, proc.SnakeCase, proc.CamelCase} {
+		caseDir := filepath.Join(*dir, fmt.Sprintf("v%d", proc.SchemaVersion), keys.String())
+		if err := os.MkdirAll(caseDir, 0755); err != nil {
+			log.Fatal(err)
+		}
+		for _, name := range proc.SchemaNames() {
+			content, err := proc.Schema(name, keys)
+			if err != nil {
+				log.Fatal(err)
+			}
+			err = os.WriteFile(filepath.Join(caseDir, name+".schema.json"), append(content, '\n'), 0644)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func demo() {
+	demoProcPidFd()
 	demoProcPidStat()
+	demoProcPidStatus()
+	demoProcAll()
+	demoProcLoadavg()
 	demoProcMeminfo()
 	demoProcNetDev()
+	demoProcNetNetstat()
 	demoProcNetSnmp()
+	demoProcNetTcp()
+	demoProcStat()
+	demoProcUptime()
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			serve(os.Args[2:])
+			return
+		case "top":
+			top(os.Args[2:])
+			return
+		case "diff":
+			diff(os.Args[2:])
+			return
+		case "json":
+			printJson(os.Args[2:])
+			return
+		case "record":
+			record(os.Args[2:])
+			return
+		case "replay":
+			replay(os.Args[2:])
+			return
+		case "schema":
+			schema(os.Args[2:])
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "%s: unknown command %q\n", programName, os.Args[1])
+			os.Exit(1)
+		}
+	}
+	demo()
 }
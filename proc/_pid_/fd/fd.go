@@ -0,0 +1,232 @@
+package fd
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docktermj/go-proc-parse/proc/_pid_/stat"
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// Types of link targets that name a kernel object rather than a path.
+const (
+	Socket    = "socket"
+	Pipe      = "pipe"
+	AnonInode = "anon_inode"
+)
+
+// A file descriptor of a process and the target of its /proc/[pid]/fd link,
+// e.g. "socket:[12345]", "pipe:[678]", "anon_inode:[eventfd]" or a path.
+// Type is "socket", "pipe", "anon_inode" or another "type:[inode]" prefix,
+// and empty for a path.  Inode is zero if the target names none.
+// References:
+// - http://man7.org/linux/man-pages/man5/proc.5.html  "/proc/[pid]/fd/"
+type Fd struct {
+	Fd     int    `json:"fd"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+	Inode  uint64 `json:"inode"`
+}
+
+// A process holding a file descriptor.
+type Owner struct {
+	Pid  int    `json:"pid"`
+	Comm string `json:"comm"`
+	Fd   int    `json:"fd"`
+}
+
+// Owners of sockets and pipes keyed by inode, as found in the Inode field of
+// tcp.Socket.  Anonymous inodes all share a single inode, so they are keyed
+// by name instead, e.g. "[eventfd]" or "inotify".  Processes whose file
+// descriptors could not be read, typically because they belong to another
+// user, are listed in Skipped.
+type Index struct {
+	Sockets    map[uint64][]Owner `json:"sockets"`
+	Pipes      map[uint64][]Owner `json:"pipes"`
+	AnonInodes map[string][]Owner `json:"anon_inodes"`
+	Skipped    []int              `json:"skipped"`
+}
+
+// Path of the directory relative to the procfs root.
+func getDirname(pid int) string {
+	return strconv.Itoa(pid) + "/fd"
+}
+
+// Path of /proc/[pid]/fd, under PROC_ROOT if it is set.  It is a directory,
+// so it is not redirected by an OS Environment variable of its own.
+func GetDirname(pid int) string {
+	return procfs.Filename(getDirname(pid))
+}
+
+// Split a link target such as "socket:[12345]" into its type and inode.
+func parseTarget(target string) (string, uint64) {
+	if strings.HasPrefix(target, AnonInode+":") {
+		return AnonInode, 0
+	}
+	splits := strings.SplitN(target, ":", 2)
+	if len(splits) != 2 || !strings.HasPrefix(splits[1], "[") || !strings.HasSuffix(splits[1], "]") {
+		return "", 0
+	}
+	inode, err := strconv.ParseUint(strings.Trim(splits[1], "[]"), 10, 64)
+	if err != nil {
+		return "", 0
+	}
+	return splits[0], inode
+}
+
+// Get file descriptors of /proc/[pid]/fd, in ascending order.
+// Example:
+//     myFds, err := fd.Get(1)
+//     x := myFds[0].Target
+func Get(pid int) ([]Fd, error) {
+	return GetFrom(procfs.Default, pid)
+}
+
+// Get file descriptors of [pid]/fd from the procfs tree fsys.  fsys must
+// implement fs.ReadLinkFS.  Descriptors closed during the scan are left out.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     myFds, err := fd.GetFrom(myFS, 1)
+func GetFrom(fsys fs.FS, pid int) ([]Fd, error) {
+
+	result := []Fd{}
+
+	// Read the directory.
+
+	dirname := getDirname(pid)
+	entries, err := fs.ReadDir(fsys, dirname)
+	if err != nil {
+		return result, err
+	}
+
+	// Read the links.
+
+	for _, entry := range entries {
+		number, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		target, err := fs.ReadLink(fsys, dirname+"/"+entry.Name())
+		if procfs.IsGone(err) {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		targetType, inode := parseTarget(target)
+		result = append(result, Fd{
+			Fd:     number,
+			Target: target,
+			Type:   targetType,
+			Inode:  inode,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Fd < result[j].Fd })
+	return result, nil
+}
+
+func GetAsJson(pid int) ([]byte, error) {
+	return GetAsJsonFrom(procfs.Default, pid)
+}
+
+func GetAsJsonFrom(fsys fs.FS, pid int) ([]byte, error) {
+	content, err := GetFrom(fsys, pid)
+	if err != nil {
+		return []byte{}, err
+	}
+	return json.Marshal(content)
+}
+
+// Get link targets of /proc/[pid]/fd as a map of strings keyed by file
+// descriptor.
+// Example:
+//     myFds := fd.GetAsMap(1)
+//     x := myFds["0"]
+func GetAsMap(pid int) (map[string]string, error) {
+	return GetAsMapFrom(procfs.Default, pid)
+}
+
+// Get link targets of [pid]/fd from the procfs tree fsys as a map of strings.
+func GetAsMapFrom(fsys fs.FS, pid int) (map[string]string, error) {
+	result := make(map[string]string)
+	fds, err := GetFrom(fsys, pid)
+	if err != nil {
+		return result, err
+	}
+	for _, aFd := range fds {
+		result[strconv.Itoa(aFd.Fd)] = aFd.Target
+	}
+	return result, nil
+}
+
+// Get the owners of every socket, pipe and anonymous inode.  Reading the file
+// descriptors of another user's process needs privileges, so such processes
+// are listed in Skipped rather than failing the scan.
+// Example:
+//     myIndex, err := fd.GetIndex()
+//     mySockets, err := tcp.Get(tcp.Tcp)
+//     x := myIndex.Sockets[mySockets[0].Inode][0].Comm
+func GetIndex() (Index, error) {
+	return GetIndexFrom(procfs.Default)
+}
+
+// Get the owners of every socket, pipe and anonymous inode in the procfs tree
+// fsys.  Processes that exit during the scan are left out, and those whose
+// file descriptors may not be read are listed in Skipped.  Any other error
+// ends the scan and is returned with the owners found so far.
+func GetIndexFrom(fsys fs.FS) (Index, error) {
+
+	result := Index{
+		Sockets:    make(map[uint64][]Owner),
+		Pipes:      make(map[uint64][]Owner),
+		AnonInodes: make(map[string][]Owner),
+		Skipped:    []int{},
+	}
+
+	pids, err := procfs.Pids(fsys)
+	if err != nil {
+		return result, err
+	}
+	for _, pid := range pids {
+		fds, err := GetFrom(fsys, pid)
+		if procfs.IsGone(err) {
+			continue
+		}
+		if errors.Is(err, fs.ErrPermission) {
+			result.Skipped = append(result.Skipped, pid)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		aStat, err := stat.GetFrom(fsys, pid)
+		if procfs.IsGone(err) {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+
+		for _, aFd := range fds {
+			owner := Owner{
+				Pid:  pid,
+				Comm: aStat.Comm,
+				Fd:   aFd.Fd,
+			}
+			switch aFd.Type {
+			case Socket:
+				result.Sockets[aFd.Inode] = append(result.Sockets[aFd.Inode], owner)
+			case Pipe:
+				result.Pipes[aFd.Inode] = append(result.Pipes[aFd.Inode], owner)
+			case AnonInode:
+				name := strings.TrimPrefix(aFd.Target, AnonInode+":")
+				result.AnonInodes[name] = append(result.AnonInodes[name], owner)
+			}
+		}
+	}
+	return result, nil
+}
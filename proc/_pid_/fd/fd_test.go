@@ -0,0 +1,29 @@
+package fd
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// Read any link target of [pid]/fd without panicking or failing.  testdata/
+// cannot hold the links, so the corpus is seeded with targets of each type.
+func FuzzGetFrom(f *testing.F) {
+	for _, target := range []string{"/dev/null", "socket:[662]", "pipe:[40622]", "anon_inode:[eventfd]", "/var/log/messages (deleted)"} {
+		f.Add(target)
+	}
+	f.Fuzz(func(t *testing.T, target string) {
+		tree := fstest.MapFS{
+			"1/stat": {Data: []byte("1 (init) S 0 1 1 0 -1 4194560 0 0 0 0 0 0 0 0 20 0 1 0 2 0 0 18446744073709551615 1 1 0 0 0 0 0 4096 1088 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+			"1/fd/3": {Data: []byte(target), Mode: fs.ModeSymlink | 0777},
+		}
+		for _, fsys := range golden.Modes(tree) {
+			_, err := GetFrom(fsys, 1)
+			golden.CheckError(t, err)
+			_, err = GetIndexFrom(fsys)
+			golden.CheckError(t, err)
+		}
+	})
+}
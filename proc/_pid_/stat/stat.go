@@ -1,11 +1,13 @@
 package stat
 
 import (
-	"bufio"
+	"bytes"
 	"encoding/json"
-	"os"
+	"fmt"
+	"io/fs"
 	"strconv"
-	"strings"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
 )
 
 // References:
@@ -66,140 +68,324 @@ type Stat struct {
 	Exit_code             int    `json:"exit_code"`
 }
 
-// Allow filename to be specified by OS Environment variable: PROC_PID_STAT
-func getFilename(pid int) string {
-	result := os.Getenv("PROC_PID_STAT")
-	if result == "" {
-		pidString := strconv.Itoa(pid)
-		result = "/proc/" + pidString + "/stat"
+// Number of fields in /proc/[pid]/stat as written by successive kernel versions.
+// Newer kernels may append fields beyond FieldsLinux3_5; they are ignored.
+const (
+	FieldsLinux2_4    = 39 // through "processor"
+	FieldsLinux2_5_19 = 41 // adds "rt_priority", "policy"
+	FieldsLinux2_6_18 = 42 // adds "delayacct_blkio_ticks"
+	FieldsLinux2_6_24 = 44 // adds "guest_time", "cguest_time"
+	FieldsLinux3_3    = 47 // adds "start_data", "end_data", "start_brk"
+	FieldsLinux3_5    = 52 // adds "arg_start", "arg_end", "env_start", "env_end", "exit_code"
+)
+
+var kernelFieldCounts = []int{
+	FieldsLinux2_4,
+	FieldsLinux2_5_19,
+	FieldsLinux2_6_18,
+	FieldsLinux2_6_24,
+	FieldsLinux3_3,
+	FieldsLinux3_5,
+}
+
+const minFields = FieldsLinux2_4
+
+// A TruncatedError reports a /proc/[pid]/stat line whose number of fields
+// does not match any kernel version, e.g. because the read was cut short.  It
+// wraps procfs.ErrShortLine, exported as proc.ErrShortLine.
+type TruncatedError struct {
+	Pid    int // process whose stat file was read
+	Fields int // number of fields found
+	Want   int // number of fields expected
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("stat: /proc/%d/stat truncated: found %d fields, want %d", e.Pid, e.Fields, e.Want)
+}
+
+func (e *TruncatedError) Unwrap() error {
+	return procfs.ErrShortLine
+}
+
+func validFieldCount(fields int) bool {
+	if fields >= FieldsLinux3_5 {
+		return true
+	}
+	for _, count := range kernelFieldCounts {
+		if fields == count {
+			return true
+		}
 	}
-	return result
+	return false
 }
 
-func asInt(value string) int {
-	result, err := strconv.Atoi(value)
-	if err != nil {
+// The smallest number of fields written by a kernel that is at least fields.
+func wantFields(fields int) int {
+	for _, count := range kernelFieldCounts {
+		if fields < count {
+			return count
+		}
+	}
+	return FieldsLinux3_5
+}
+
+// Path of the file relative to the procfs root.
+func getFilename(pid int) string {
+	return strconv.Itoa(pid) + "/stat"
+}
+
+// Allow filename to be specified by OS Environment variable: PROC_PID_STAT
+func GetFilename(pid int) string {
+	return procfs.Filename(getFilename(pid))
+}
+
+// Fields not written by older kernels are empty.  They are left as zero
+// rather than reported.
+func asInt(check *procfs.Checker, field string, value []byte) int {
+	if len(value) == 0 {
 		return int(0)
 	}
-	return result
+	return int(check.Int64Bytes(field, value))
 }
 
-func asUint(value string) uint {
-	result, err := strconv.ParseUint(value, 10, 32)
-	if err != nil {
+func asUint(check *procfs.Checker, field string, value []byte) uint {
+	if len(value) == 0 {
 		return uint(0)
 	}
-	return uint(result)
+	return uint(check.Uint64Bytes(field, value))
 }
 
-func asInt64(value string) int64 {
-	result, err := strconv.ParseInt(value, 10, 64)
-	if err != nil {
+func asInt64(check *procfs.Checker, field string, value []byte) int64 {
+	if len(value) == 0 {
 		return int64(0)
 	}
-	return result
+	return check.Int64Bytes(field, value)
 }
 
-func asUint64(value string) uint64 {
-	result, err := strconv.ParseUint(value, 10, 64)
-	if err != nil {
+func asUint64(check *procfs.Checker, field string, value []byte) uint64 {
+	if len(value) == 0 {
 		return uint64(0)
 	}
-	return result
+	return check.Uint64Bytes(field, value)
+}
+
+// Keep old if value has not changed, so that polling the same process does
+// not allocate a new string each time.
+func asString(old string, value []byte) string {
+	if string(value) == old {
+		return old
+	}
+	return string(value)
 }
 
 func Get(pid int) (Stat, error) {
+	return GetFrom(procfs.Default, pid)
+}
+
+// Get values of [pid]/stat from the procfs tree fsys.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     myStat, err := stat.GetFrom(myFS, 1)
+func GetFrom(fsys fs.FS, pid int) (Stat, error) {
 
 	result := Stat{}
 
-	// Open the file.
+	// Read the file in a single read.
 
-	fileName := getFilename(pid)
-	file, err := os.Open(fileName)
+	data, err := procfs.ReadFile(fsys, getFilename(pid))
 	if err != nil {
 		return result, err
 	}
 
-	// Read the file.
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		inputLine := scanner.Text()
-		splits := strings.Fields(inputLine)
-
-		result.Pid = asInt(splits[0])
-		result.Comm = splits[1]
-		result.State = splits[2]
-		result.Ppid = asInt(splits[3])
-		result.Pgrp = asInt(splits[4])
-		result.Session = asInt(splits[5])
-		result.Tty_nr = asInt(splits[6])
-		result.Tpgid = asInt(splits[7])
-		result.Flags = asUint(splits[8])
-		result.Minflt = asUint64(splits[9])
-		result.Cminflt = asUint64(splits[10])
-		result.Majflt = asUint64(splits[11])
-		result.Cmajflt = asUint64(splits[12])
-		result.Utime = asUint64(splits[13])
-		result.Stime = asUint64(splits[14])
-		result.Cutime = asInt64(splits[15])
-		result.Cstime = asInt64(splits[16])
-		result.Priority = asInt64(splits[17])
-		result.Nice = asInt64(splits[18])
-		result.Num_threads = asInt64(splits[19])
-		result.Itrealvalue = asInt64(splits[20])
-		result.Starttime = asUint64(splits[21])
-		result.Vsize = asUint64(splits[22])
-		result.Rss = asInt64(splits[23])
-		result.Rsslim = asUint64(splits[24])
-		result.Startcode = asUint64(splits[25])
-		result.Endcode = asUint64(splits[26])
-		result.Startstack = asUint64(splits[27])
-		result.Kstkesp = asUint64(splits[28])
-		result.Kstkeip = asUint64(splits[29])
-		result.Signal = asUint64(splits[30])
-		result.Blocked = asUint64(splits[31])
-		result.Sigignore = asUint64(splits[32])
-		result.Sigcatch = asUint64(splits[33])
-		result.Wchan = asUint64(splits[34])
-		result.Nswap = asUint64(splits[35])
-		result.Cnswap = asUint64(splits[36])
-		result.Exit_signal = asInt(splits[37])
-		result.Processor = asInt(splits[38])
-		result.Rt_priority = asUint(splits[39])
-		result.Policy = asUint(splits[40])
-		result.Delayacct_blkio_ticks = asUint64(splits[41])
-		result.Guest_time = asUint64(splits[42])
-		result.Cguest_time = asInt64(splits[43])
-		result.Start_data = asUint64(splits[44])
-		result.End_data = asUint64(splits[45])
-		result.Start_brk = asUint64(splits[46])
-		result.Arg_start = asUint64(splits[47])
-		result.Arg_end = asUint64(splits[48])
-		result.Env_start = asUint64(splits[49])
-		result.Env_end = asUint64(splits[50])
-		result.Exit_code = asInt(splits[51])
+	// Parse the file.
+
+	err = ParseInto(fsys, pid, data, &result)
+	return result, err
+}
+
+// Parse data read from [pid]/stat of the procfs tree fsys into result, which
+// may hold the values of an earlier read.  fsys is only consulted for its
+// parse mode.  It does not allocate unless comm or state changed or a value
+// cannot be parsed, so it suits polling many processes.
+// Example:
+//     myStat := stat.Stat{}
+//     data, err := os.ReadFile("/proc/1/stat")
+//     err = stat.ParseInto(os.DirFS("/proc"), 1, data, &myStat)
+func ParseInto(fsys fs.FS, pid int, data []byte, result *Stat) error {
+	check := procfs.NewProcessChecker(fsys, pid, "stat")
+	check.NextLine()
+	inputLine, _ := procfs.NextLine(data)
+	if err := parse(check, pid, inputLine, result); err != nil {
+		return err
+	}
+	return check.Err()
+}
+
+// Parse a /proc/[pid]/stat line.  The comm field is the only one that may
+// contain spaces or parentheses, so it is delimited by the first "(" and the
+// last ")".
+func parse(check *procfs.Checker, pid int, inputLine []byte, result *Stat) error {
+
+	// Pull out the comm field.
+
+	commStart := bytes.IndexByte(inputLine, '(')
+	commEnd := bytes.LastIndexByte(inputLine, ')')
+	if commStart < 0 || commEnd < commStart {
+		return &TruncatedError{Pid: pid, Fields: len(bytes.Fields(inputLine)), Want: minFields}
+	}
+	splits := [FieldsLinux3_5][]byte{}
+	splits[0] = bytes.TrimSpace(inputLine[:commStart])
+	splits[1] = inputLine[commStart+1 : commEnd]
+	fields := 2
+	rest := inputLine[commEnd+1:]
+	for {
+		var field []byte
+		field, rest = procfs.NextField(rest)
+		if len(field) == 0 {
+			break
+		}
+		if fields < len(splits) {
+			splits[fields] = field
+		}
+		fields++
+	}
+
+	// Validate the number of fields against those written by known kernels.
+	// Fields not written by older kernels are left empty, and so zero.
+
+	if !validFieldCount(fields) {
+		return &TruncatedError{Pid: pid, Fields: fields, Want: wantFields(fields)}
+	}
+
+	// Pull out the values.
+
+	result.Pid = asInt(check, "pid", splits[0])
+	result.Comm = asString(result.Comm, splits[1])
+	result.State = asString(result.State, splits[2])
+	result.Ppid = asInt(check, "ppid", splits[3])
+	result.Pgrp = asInt(check, "pgrp", splits[4])
+	result.Session = asInt(check, "session", splits[5])
+	result.Tty_nr = asInt(check, "tty_nr", splits[6])
+	result.Tpgid = asInt(check, "tpgid", splits[7])
+	result.Flags = asUint(check, "flags", splits[8])
+	result.Minflt = asUint64(check, "minflt", splits[9])
+	result.Cminflt = asUint64(check, "cminflt", splits[10])
+	result.Majflt = asUint64(check, "majflt", splits[11])
+	result.Cmajflt = asUint64(check, "cmajflt", splits[12])
+	result.Utime = asUint64(check, "utime", splits[13])
+	result.Stime = asUint64(check, "stime", splits[14])
+	result.Cutime = asInt64(check, "cutime", splits[15])
+	result.Cstime = asInt64(check, "cstime", splits[16])
+	result.Priority = asInt64(check, "priority", splits[17])
+	result.Nice = asInt64(check, "nice", splits[18])
+	result.Num_threads = asInt64(check, "num_threads", splits[19])
+	result.Itrealvalue = asInt64(check, "itrealvalue", splits[20])
+	result.Starttime = asUint64(check, "starttime", splits[21])
+	result.Vsize = asUint64(check, "vsize", splits[22])
+	result.Rss = asInt64(check, "rss", splits[23])
+	result.Rsslim = asUint64(check, "rsslim", splits[24])
+	result.Startcode = asUint64(check, "startcode", splits[25])
+	result.Endcode = asUint64(check, "endcode", splits[26])
+	result.Startstack = asUint64(check, "startstack", splits[27])
+	result.Kstkesp = asUint64(check, "kstkesp", splits[28])
+	result.Kstkeip = asUint64(check, "kstkeip", splits[29])
+	result.Signal = asUint64(check, "signal", splits[30])
+	result.Blocked = asUint64(check, "blocked", splits[31])
+	result.Sigignore = asUint64(check, "sigignore", splits[32])
+	result.Sigcatch = asUint64(check, "sigcatch", splits[33])
+	result.Wchan = asUint64(check, "wchan", splits[34])
+	result.Nswap = asUint64(check, "nswap", splits[35])
+	result.Cnswap = asUint64(check, "cnswap", splits[36])
+	result.Exit_signal = asInt(check, "exit_signal", splits[37])
+	result.Processor = asInt(check, "processor", splits[38])
+	result.Rt_priority = asUint(check, "rt_priority", splits[39])
+	result.Policy = asUint(check, "policy", splits[40])
+	result.Delayacct_blkio_ticks = asUint64(check, "delayacct_blkio_ticks", splits[41])
+	result.Guest_time = asUint64(check, "guest_time", splits[42])
+	result.Cguest_time = asInt64(check, "cguest_time", splits[43])
+	result.Start_data = asUint64(check, "start_data", splits[44])
+	result.End_data = asUint64(check, "end_data", splits[45])
+	result.Start_brk = asUint64(check, "start_brk", splits[46])
+	result.Arg_start = asUint64(check, "arg_start", splits[47])
+	result.Arg_end = asUint64(check, "arg_end", splits[48])
+	result.Env_start = asUint64(check, "env_start", splits[49])
+	result.Env_end = asUint64(check, "env_end", splits[50])
+	result.Exit_code = asInt(check, "exit_code", splits[51])
+	return nil
+}
+
+// Get values of /proc/[pid]/stat for every process, in ascending pid order.
+// Processes that exit during the scan are left out.
+// Example:
+//     myStats, err := stat.GetAll()
+//     x := myStats[0].Comm
+func GetAll() ([]Stat, error) {
+	return GetAllFrom(procfs.Default)
+}
+
+// Get values of [pid]/stat for every process in the procfs tree fsys.
+func GetAllFrom(fsys fs.FS) ([]Stat, error) {
+
+	result := []Stat{}
+
+	pids, err := procfs.Pids(fsys)
+	if err != nil {
+		return result, err
+	}
+	for _, pid := range pids {
+		stat, err := GetFrom(fsys, pid)
+		if procfs.IsGone(err) {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result = append(result, stat)
 	}
 	return result, nil
 }
 
 func GetAsJson(pid int) ([]byte, error) {
-	content, err := Get(pid)
+	return GetAsJsonFrom(procfs.Default, pid)
+}
+
+func GetAsJsonFrom(fsys fs.FS, pid int) ([]byte, error) {
+	content, err := GetFrom(fsys, pid)
 	if err != nil {
 		return []byte{}, err
 	}
-	result, _ := json.Marshal(content)
-	return result, nil
+	return json.Marshal(content)
+}
+
+// Get values of /proc/[pid]/stat as JSON with the keys of struct fields in the case
+// keys.  Keys of maps are left as they are.
+// Example:
+//     myJson, err := stat.GetAsJsonKeys(1, proc.CamelCase) // {"ttyNr":...}
+func GetAsJsonKeys(pid int, keys procfs.KeyCase) ([]byte, error) {
+	return GetAsJsonKeysFrom(procfs.Default, pid, keys)
 }
 
-// Get values of /proc/meminfo as a map of uint64.
+func GetAsJsonKeysFrom(fsys fs.FS, pid int, keys procfs.KeyCase) ([]byte, error) {
+	content, err := GetFrom(fsys, pid)
+	if err != nil {
+		return []byte{}, err
+	}
+	return procfs.MarshalJSON(content, keys)
+}
+
+// Get values of /proc/[pid]/stat as a map of interface{}.
 // Example:
-//     myMeminfo := meminfo.Get()
-//     x := myMeminfo["MemTotal"]
+//     myStat := stat.GetAsMap(1)
+//     x := myStat["utime"]
 func GetAsMap(pid int) (map[string]interface{}, error) {
+	return GetAsMapFrom(procfs.Default, pid)
+}
+
+// Get values of [pid]/stat from the procfs tree fsys as a map of interface{}.
+func GetAsMapFrom(fsys fs.FS, pid int) (map[string]interface{}, error) {
 
 	result := make(map[string]interface{})
-	stat, err := Get(pid)
+	stat, err := GetFrom(fsys, pid)
 	if err != nil {
 		return result, err
 	}
@@ -0,0 +1,185 @@
+package stat
+
+import (
+	"errors"
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"io/fs"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// The trees under testdata/, read from an fs.FS and, through PROC_ROOT, from
+// the default procfs tree.
+func TestGolden(t *testing.T) {
+	for _, tree := range golden.Trees(t) {
+		t.Run(tree.Name, func(t *testing.T) {
+			content, err := GetFrom(tree.FS, tree.Pid)
+			golden.Check(t, tree, "pid_stat", content, err)
+		})
+		t.Run(tree.Name+"/PROC_ROOT", func(t *testing.T) {
+			tree.SetRoot(t)
+			content, err := Get(tree.Pid)
+			golden.Check(t, tree, "pid_stat", content, err)
+		})
+	}
+}
+
+// The comm of a process, which may hold spaces and parentheses, and the
+// fields after it.
+func TestGetFrom(t *testing.T) {
+	tests := []struct {
+		tree  string
+		comm  string
+		state string
+	}{
+		{"synthetic-linux-4.15-ubuntu18.04-x86_64", "tmux: server", "S"},
+		{"synthetic-linux-5.10-raspbian-armv7l", "kworker/0:1-events", "I"},
+		{"synthetic-linux-5.15-ubuntu22.04-x86_64", "(sd-pam)", "S"},
+	}
+	for _, test := range tests {
+		tree := golden.Find(t, test.tree)
+		stat, err := GetFrom(tree.FS, tree.Pid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stat.Pid != tree.Pid || stat.Comm != test.comm || stat.State != test.state {
+			t.Errorf("%s: got %d (%s) %s, want %d (%s) %s", test.tree, stat.Pid, stat.Comm, stat.State, tree.Pid, test.comm, test.state)
+		}
+	}
+}
+
+// A [pid]/stat line of linux-6.18-container-x86_64.
+const statLine = "1 (process_api) S 0 0 0 0 -1 4194560 56199 7441743 69 442 325 545 22156 3967 20 0 6 0 7 24416256 2350 18446744073709551615 1 1 0 0 0 0 0 4096 1088 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n"
+
+// Malformed values give a ParseError in strict mode and a warning in lenient
+// mode; the other fields are still read.
+func TestGetFromMalformed(t *testing.T) {
+	expected, err := GetFrom(fstest.MapFS{"1/stat": {Data: []byte(statLine)}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noFlags := expected
+	noFlags.Flags = 0
+	noTimes := expected
+	noTimes.Utime, noTimes.Rss = 0, 0
+	tests := []golden.Malformed{
+		{
+			Name:     "value",
+			Data:     strings.Replace(statLine, " 4194560 ", " 4194x60 ", 1),
+			Expected: noFlags,
+			Wants:    []procfs.ParseError{{File: "1/stat", Line: 1, Field: "flags", Text: "4194x60", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "two values",
+			Data:     strings.Replace(strings.Replace(statLine, " 325 545 ", " -325 545 ", 1), " 2350 ", " 2350x ", 1),
+			Expected: noTimes,
+			Wants: []procfs.ParseError{
+				{File: "1/stat", Line: 1, Field: "utime", Text: "-325", Err: strconv.ErrSyntax},
+				{File: "1/stat", Line: 1, Field: "rss", Text: "2350x", Err: strconv.ErrSyntax},
+			},
+		},
+	}
+	for _, test := range tests {
+		golden.CheckMalformed(t, "1/stat", test, func(fsys fs.FS) (interface{}, error) { return GetFrom(fsys, 1) })
+	}
+}
+
+// statLine cut to its first fields fields.
+func statFields(fields int) string {
+	return strings.Join(strings.Fields(statLine)[:fields], " ") + "\n"
+}
+
+// The comm is read from the first "(" to the last ")", so it may hold spaces
+// and parentheses of its own.
+func TestGetFromComm(t *testing.T) {
+	rest := strings.SplitN(statLine, ") ", 2)[1]
+	tests := []struct {
+		comm     string
+		expected string
+	}{
+		{"(a) b))", "a) b)"},
+		{"(sleep 1)", "sleep 1"},
+		{"(((x)))", "((x))"},
+		{"(a (S) 0 0)", "a (S) 0 0"},
+		{"()", ""},
+	}
+	for _, test := range tests {
+		tree := procfs.Strict(fstest.MapFS{"1/stat": {Data: []byte("1 " + test.comm + " " + rest)}})
+		stat, err := GetFrom(tree, 1)
+		if err != nil {
+			t.Fatalf("%s: %v", test.comm, err)
+		}
+		if stat.Comm != test.expected || stat.State != "S" || stat.Num_threads != 6 || stat.Exit_signal != 17 {
+			t.Errorf("%s: got comm %q, state %q, num_threads %d, exit_signal %d", test.comm, stat.Comm, stat.State, stat.Num_threads, stat.Exit_signal)
+		}
+	}
+}
+
+// A line with a number of fields that no kernel writes, or without a comm,
+// gives a TruncatedError in every mode.
+func TestGetFromTruncated(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		fields int
+		want   int
+	}{
+		{"too short", statFields(38), 38, FieldsLinux2_4},
+		{"between 2.4 and 2.5.19", statFields(40), 40, FieldsLinux2_5_19},
+		{"between 2.6.24 and 3.3", statFields(45), 45, FieldsLinux3_3},
+		{"between 3.3 and 3.5", statFields(50), 50, FieldsLinux3_5},
+		{"no comm", "1 process_api S 0 0 0\n", 6, FieldsLinux2_4},
+		{"comm cut short", "1 (process_a", 2, FieldsLinux2_4},
+		{"empty", "", 0, FieldsLinux2_4},
+	}
+	for _, test := range tests {
+		tree := fstest.MapFS{"1/stat": {Data: []byte(test.data)}}
+		for _, fsys := range []fs.FS{tree, procfs.Strict(tree), procfs.Lenient(tree, &procfs.Warnings{})} {
+			_, err := GetFrom(fsys, 1)
+			var truncatedError *TruncatedError
+			if !errors.As(err, &truncatedError) {
+				t.Errorf("%s: got %v, want a TruncatedError", test.name, err)
+				continue
+			}
+			if *truncatedError != (TruncatedError{Pid: 1, Fields: test.fields, Want: test.want}) {
+				t.Errorf("%s: got %+v, want %d fields of %d", test.name, *truncatedError, test.fields, test.want)
+			}
+			if !errors.Is(err, procfs.ErrShortLine) {
+				t.Errorf("%s: %v does not wrap ErrShortLine", test.name, err)
+			}
+		}
+	}
+
+	// The field counts of known kernels, and more, parse.
+
+	for _, fields := range []int{FieldsLinux2_4, FieldsLinux2_5_19, FieldsLinux2_6_18, FieldsLinux2_6_24, FieldsLinux3_3, FieldsLinux3_5, 53} {
+		data := statFields(min(fields, FieldsLinux3_5))
+		if fields > FieldsLinux3_5 {
+			data = strings.TrimSuffix(data, "\n") + " 0\n"
+		}
+		stat, err := GetFrom(procfs.Strict(fstest.MapFS{"1/stat": {Data: []byte(data)}}), 1)
+		if err != nil || stat.Comm != "process_api" {
+			t.Errorf("%d fields: got %q, %v", fields, stat.Comm, err)
+		}
+	}
+}
+
+// Parse any [pid]/stat without panicking, into a result, a ParseError or a
+// TruncatedError, also into the result of an earlier parse as a Parser does.
+func FuzzGetFrom(f *testing.F) {
+	golden.Seed(f, "[pid]/stat")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, fsys := range golden.Modes(fstest.MapFS{"1/stat": {Data: data}}) {
+			var truncatedError *TruncatedError
+			result, err := GetFrom(fsys, 1)
+			golden.CheckError(t, err, &truncatedError)
+			golden.CheckError(t, ParseInto(fsys, 1, data, &result), &truncatedError)
+			_, err = GetAsMapFrom(fsys, 1)
+			golden.CheckError(t, err, &truncatedError)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package stat
+
+import (
+	"encoding/binary"
+	"io/fs"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Clock ticks per second (USER_HZ), the unit of Utime, Stime, Cutime, Cstime
+// and Starttime, as sysconf(_SC_CLK_TCK) reports it.  It may be set, e.g. to
+// read a procfs tree captured on another system.
+var ClockTicks uint64 = clockTicks()
+
+// Size of a memory page in bytes, the unit of Rss.  It may be set like
+// ClockTicks.
+var PageSize uint64 = uint64(os.Getpagesize())
+
+// Key of AT_CLKTCK in the auxiliary vector.
+// References:
+// - include/uapi/linux/auxvec.h
+const atClkTck = 17
+
+// Read AT_CLKTCK from /proc/self/auxv, which is where the C library gets
+// _SC_CLK_TCK.  Linux has used 100 on every common architecture, so that is
+// the fallback.
+// References:
+// - http://man7.org/linux/man-pages/man3/getauxval.3.html
+func clockTicks() uint64 {
+	return clockTicksFrom(os.DirFS("/proc"), "self/auxv")
+}
+
+// Read AT_CLKTCK from the auxiliary vector name in fsys, in the word size and
+// byte order of this system, or return 100.
+func clockTicksFrom(fsys fs.FS, name string) uint64 {
+	auxv, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return 100
+	}
+	wordSize := strconv.IntSize / 8
+	word := func(b []byte) uint64 {
+		if wordSize == 4 {
+			return uint64(binary.NativeEndian.Uint32(b))
+		}
+		return binary.NativeEndian.Uint64(b)
+	}
+	for index := 0; index+2*wordSize <= len(auxv); index += 2 * wordSize {
+		key := word(auxv[index:])
+		if key == atClkTck {
+			if value := word(auxv[index+wordSize:]); value > 0 {
+				return value
+			}
+		}
+	}
+	return 100
+}
+
+// Convert clock ticks to a duration without overflowing for large counts.
+func ticksAsDuration(ticks uint64) time.Duration {
+	hertz := ClockTicks
+	if hertz == 0 {
+		hertz = 100
+	}
+	seconds := ticks / hertz
+	remainder := ticks % hertz
+	return time.Duration(seconds)*time.Second + time.Duration(remainder)*time.Second/time.Duration(hertz)
+}
+
+// Negative child times are not expected; treat them as zero.
+func signedTicksAsDuration(ticks int64) time.Duration {
+	if ticks < 0 {
+		return 0
+	}
+	return ticksAsDuration(uint64(ticks))
+}
+
+// Time the process has been scheduled in user mode.
+func (s Stat) UserTime() time.Duration {
+	return ticksAsDuration(s.Utime)
+}
+
+// Time the process has been scheduled in kernel mode.
+func (s Stat) SystemTime() time.Duration {
+	return ticksAsDuration(s.Stime)
+}
+
+// Time the process has been scheduled in user and kernel mode.
+// Example:
+//     myStat, err := stat.Get(1)
+//     x := myStat.CPUTime().Seconds()
+func (s Stat) CPUTime() time.Duration {
+	return s.UserTime() + s.SystemTime()
+}
+
+// Time the process's waited-for children have been scheduled in user and
+// kernel mode.
+func (s Stat) ChildrenCPUTime() time.Duration {
+	return signedTicksAsDuration(s.Cutime) + signedTicksAsDuration(s.Cstime)
+}
+
+// Time the process started.  Starttime is relative to boot, so the Btime
+// field of /proc/stat is needed.
+// Example:
+//     myStat, err := stat.Get(1)
+//     mySystemStat, err := sysstat.Get() // package "github.com/docktermj/go-proc-parse/proc/stat"
+//     x := myStat.StartTime(mySystemStat.Btime)
+func (s Stat) StartTime(btime uint64) time.Time {
+	return time.Unix(int64(btime), 0).Add(ticksAsDuration(s.Starttime))
+}
+
+// Resident set size in bytes.
+func (s Stat) RSSBytes() uint64 {
+	if s.Rss < 0 {
+		return 0
+	}
+	return uint64(s.Rss) * PageSize
+}
@@ -0,0 +1,150 @@
+package stat
+
+import (
+	"encoding/binary"
+	"strconv"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// Set ClockTicks and PageSize for a test.
+func setUnits(t *testing.T, clockTicks uint64, pageSize uint64) {
+	savedClockTicks, savedPageSize := ClockTicks, PageSize
+	ClockTicks, PageSize = clockTicks, pageSize
+	t.Cleanup(func() { ClockTicks, PageSize = savedClockTicks, savedPageSize })
+}
+
+// An auxiliary vector of pairs of words in the word size and byte order of
+// this system.
+func auxv(words ...uint64) []byte {
+	result := []byte{}
+	for _, word := range words {
+		if strconv.IntSize == 32 {
+			result = binary.NativeEndian.AppendUint32(result, uint32(word))
+			continue
+		}
+		result = binary.NativeEndian.AppendUint64(result, word)
+	}
+	return result
+}
+
+func TestClockTicksFrom(t *testing.T) {
+	tests := []struct {
+		name     string
+		auxv     []byte
+		expected uint64
+	}{
+		{"AT_CLKTCK", auxv(6, 4096, atClkTck, 250, 0, 0), 250},
+		{"AT_CLKTCK last", auxv(6, 4096, atClkTck, 1000), 1000},
+		{"AT_CLKTCK of 0", auxv(atClkTck, 0, 0, 0), 100},
+		{"no AT_CLKTCK", auxv(6, 4096, 0, 0), 100},
+		{"truncated pair", auxv(6, 4096, atClkTck), 100},
+		{"empty", []byte{}, 100},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"self/auxv": {Data: test.auxv}}
+			if actual := clockTicksFrom(fsys, "self/auxv"); actual != test.expected {
+				t.Errorf("clockTicksFrom = %d; want %d", actual, test.expected)
+			}
+		})
+	}
+
+	// Unreadable.
+
+	if actual := clockTicksFrom(fstest.MapFS{}, "self/auxv"); actual != 100 {
+		t.Errorf("clockTicksFrom without auxv = %d; want 100", actual)
+	}
+
+	// The auxv captured with linux-6.18-container-x86_64, of a 64-bit little
+	// endian system.
+
+	if strconv.IntSize != 64 || binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("not a 64-bit little-endian system")
+	}
+	for _, tree := range golden.Trees(t) {
+		if tree.Name == "linux-6.18-container-x86_64" {
+			if actual := clockTicksFrom(tree.FS, "self/auxv"); actual != 100 {
+				t.Errorf("%s: clockTicksFrom = %d; want 100", tree.Name, actual)
+			}
+		}
+	}
+}
+
+func TestTicksAsDuration(t *testing.T) {
+	tests := []struct {
+		clockTicks uint64
+		ticks      uint64
+		expected   time.Duration
+	}{
+		{100, 0, 0},
+		{100, 150, 1500 * time.Millisecond},
+		{1000, 1234, 1234 * time.Millisecond},
+		{300, 1, time.Second / 300},
+		{0, 150, 1500 * time.Millisecond},             // Unset: 100.
+		{100, 900000000000, 9000000000 * time.Second}, // ticks * time.Second overflows.
+		{250, 18446744073, 73786976*time.Second + 292*time.Millisecond},
+	}
+	for _, test := range tests {
+		setUnits(t, test.clockTicks, 4096)
+		if actual := ticksAsDuration(test.ticks); actual != test.expected {
+			t.Errorf("ticksAsDuration(%d) at %d Hz = %v; want %v", test.ticks, test.clockTicks, actual, test.expected)
+		}
+	}
+	setUnits(t, 100, 4096)
+	if actual := signedTicksAsDuration(-5); actual != 0 {
+		t.Errorf("signedTicksAsDuration(-5) = %v; want 0", actual)
+	}
+	if actual := (Stat{Utime: 325, Stime: 545}).CPUTime(); actual != 8700*time.Millisecond {
+		t.Errorf("CPUTime = %v; want 8.7s", actual)
+	}
+	if actual := (Stat{Cutime: 22156, Cstime: -1}).ChildrenCPUTime(); actual != 221560*time.Millisecond {
+		t.Errorf("ChildrenCPUTime = %v; want 3m41.56s", actual)
+	}
+}
+
+// StartTime and RSSBytes of the process of each tree under testdata/, at 100
+// Hz and 4096-byte pages.  btime is that of linux-6.18-container-x86_64.
+func TestStartTimeAndRSSBytes(t *testing.T) {
+	setUnits(t, 100, 4096)
+	const btime = 1792309001
+	tests := map[string]struct {
+		startTime time.Time
+		rssBytes  uint64
+	}{
+		"synthetic-linux-2.6.32-centos6-x86_64":   {time.Unix(btime, 30000000), 390 * 4096},
+		"synthetic-linux-3.10-centos7-x86_64":     {time.Unix(btime, 40000000), 1709 * 4096},
+		"synthetic-linux-4.15-ubuntu18.04-x86_64": {time.Unix(btime+1124, 580000000), 1236 * 4096},
+		"synthetic-linux-5.10-raspbian-armv7l":    {time.Unix(btime+2, 140000000), 0},
+		"synthetic-linux-5.15-ubuntu22.04-x86_64": {time.Unix(btime+16, 120000000), 1196 * 4096},
+		"linux-6.18-container-x86_64":             {time.Unix(btime, 70000000), 2350 * 4096},
+	}
+	for _, tree := range golden.Trees(t) {
+		t.Run(tree.Name, func(t *testing.T) {
+			expected, ok := tests[tree.Name]
+			if !ok {
+				t.Fatalf("no test of %s", tree.Name)
+			}
+			aStat, err := GetFrom(tree.FS, tree.Pid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := aStat.StartTime(btime); !actual.Equal(expected.startTime) {
+				t.Errorf("StartTime = %v; want %v", actual, expected.startTime)
+			}
+			if actual := aStat.RSSBytes(); actual != expected.rssBytes {
+				t.Errorf("RSSBytes = %d; want %d", actual, expected.rssBytes)
+			}
+		})
+	}
+	if actual := (Stat{Rss: -1}).RSSBytes(); actual != 0 {
+		t.Errorf("RSSBytes of a negative Rss = %d; want 0", actual)
+	}
+	setUnits(t, 100, 16384)
+	if actual := (Stat{Rss: 3}).RSSBytes(); actual != 3*16384 {
+		t.Errorf("RSSBytes at 16384-byte pages = %d; want %d", actual, 3*16384)
+	}
+}
@@ -0,0 +1,97 @@
+package status
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// A capability set as found in the CapInh, CapPrm, CapEff, CapBnd and CapAmb
+// fields of /proc/[pid]/status.  Bit n is set if capability n is in the set.
+// References:
+// - http://man7.org/linux/man-pages/man7/capabilities.7.html
+type Capabilities uint64
+
+// Capability names indexed by capability number.
+// References:
+// - include/uapi/linux/capability.h
+var capabilityNames = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN",
+	"CAP_NET_RAW",
+	"CAP_IPC_LOCK",
+	"CAP_IPC_OWNER",
+	"CAP_SYS_MODULE",
+	"CAP_SYS_RAWIO",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_BOOT",
+	"CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG",
+	"CAP_MKNOD",
+	"CAP_LEASE",
+	"CAP_AUDIT_WRITE",
+	"CAP_AUDIT_CONTROL",
+	"CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE",
+	"CAP_MAC_ADMIN",
+	"CAP_SYSLOG",
+	"CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND",
+	"CAP_AUDIT_READ",
+	"CAP_PERFMON",
+	"CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// Name of capability number n, e.g. "CAP_NET_ADMIN".  Capabilities newer than
+// this package are named by number, e.g. "CAP_41".
+func CapabilityName(n int) string {
+	if n >= 0 && n < len(capabilityNames) {
+		return capabilityNames[n]
+	}
+	return "CAP_" + strconv.Itoa(n)
+}
+
+// Has reports whether capability number n is in the set.
+func (c Capabilities) Has(n int) bool {
+	return n >= 0 && n < 64 && c&(1<<uint(n)) != 0
+}
+
+// Names of the capabilities in the set, in capability number order.
+// Example:
+//     myStatus, err := status.Get(1)
+//     x := myStatus.CapEff.Names() // ["CAP_CHOWN", "CAP_DAC_OVERRIDE", ...]
+func (c Capabilities) Names() []string {
+	result := []string{}
+	for n := 0; n < 64; n++ {
+		if c.Has(n) {
+			result = append(result, CapabilityName(n))
+		}
+	}
+	return result
+}
+
+func (c Capabilities) String() string {
+	return strings.Join(c.Names(), ",")
+}
+
+// Capability sets are written to JSON as a list of capability names.
+func (c Capabilities) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Names())
+}
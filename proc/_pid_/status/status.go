@@ -0,0 +1,393 @@
+package status
+
+import (
+	"encoding/json"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// Real, effective, saved set, and filesystem IDs from the Uid and Gid fields.
+type IDs struct {
+	Real       uint32 `json:"Real"`
+	Effective  uint32 `json:"Effective"`
+	SavedSet   uint32 `json:"SavedSet"`
+	Filesystem uint32 `json:"Filesystem"`
+}
+
+// Mode of the Seccomp field.
+type SeccompMode int
+
+const (
+	SeccompDisabled SeccompMode = 0
+	SeccompStrict   SeccompMode = 1
+	SeccompFilter   SeccompMode = 2
+)
+
+func (m SeccompMode) String() string {
+	switch m {
+	case SeccompDisabled:
+		return "disabled"
+	case SeccompStrict:
+		return "strict"
+	case SeccompFilter:
+		return "filter"
+	}
+	return strconv.Itoa(int(m))
+}
+
+// Memory fields, reported by the kernel in kB, are normalized to bytes.
+// References:
+// - Linux command: man 5 proc
+// - http://man7.org/linux/man-pages/man5/proc.5.html  "/proc/[pid]/status"
+type Status struct {
+	Name                       string       `json:"Name"`
+	Umask                      uint32       `json:"Umask"`
+	State                      string       `json:"State"`
+	Tgid                       int          `json:"Tgid"`
+	Ngid                       int          `json:"Ngid"`
+	Pid                        int          `json:"Pid"`
+	PPid                       int          `json:"PPid"`
+	TracerPid                  int          `json:"TracerPid"`
+	Uid                        IDs          `json:"Uid"`
+	Gid                        IDs          `json:"Gid"`
+	FDSize                     uint64       `json:"FDSize"`
+	Groups                     []uint32     `json:"Groups"`
+	NStgid                     []int        `json:"NStgid"`
+	NSpid                      []int        `json:"NSpid"`
+	NSpgid                     []int        `json:"NSpgid"`
+	NSsid                      []int        `json:"NSsid"`
+	VmPeak                     uint64       `json:"VmPeak"`
+	VmSize                     uint64       `json:"VmSize"`
+	VmLck                      uint64       `json:"VmLck"`
+	VmPin                      uint64       `json:"VmPin"`
+	VmHWM                      uint64       `json:"VmHWM"`
+	VmRSS                      uint64       `json:"VmRSS"`
+	RssAnon                    uint64       `json:"RssAnon"`
+	RssFile                    uint64       `json:"RssFile"`
+	RssShmem                   uint64       `json:"RssShmem"`
+	VmData                     uint64       `json:"VmData"`
+	VmStk                      uint64       `json:"VmStk"`
+	VmExe                      uint64       `json:"VmExe"`
+	VmLib                      uint64       `json:"VmLib"`
+	VmPTE                      uint64       `json:"VmPTE"`
+	VmSwap                     uint64       `json:"VmSwap"`
+	HugetlbPages               uint64       `json:"HugetlbPages"`
+	Threads                    int64        `json:"Threads"`
+	SigPnd                     uint64       `json:"SigPnd"`
+	ShdPnd                     uint64       `json:"ShdPnd"`
+	SigBlk                     uint64       `json:"SigBlk"`
+	SigIgn                     uint64       `json:"SigIgn"`
+	SigCgt                     uint64       `json:"SigCgt"`
+	CapInh                     Capabilities `json:"CapInh"`
+	CapPrm                     Capabilities `json:"CapPrm"`
+	CapEff                     Capabilities `json:"CapEff"`
+	CapBnd                     Capabilities `json:"CapBnd"`
+	CapAmb                     Capabilities `json:"CapAmb"`
+	NoNewPrivs                 bool         `json:"NoNewPrivs"`
+	Seccomp                    SeccompMode  `json:"Seccomp"`
+	Cpus_allowed_list          string       `json:"Cpus_allowed_list"`
+	Mems_allowed_list          string       `json:"Mems_allowed_list"`
+	Voluntary_ctxt_switches    uint64       `json:"voluntary_ctxt_switches"`
+	Nonvoluntary_ctxt_switches uint64       `json:"nonvoluntary_ctxt_switches"`
+}
+
+// Path of the file relative to the procfs root.
+func getFilename(pid int) string {
+	return strconv.Itoa(pid) + "/status"
+}
+
+// Allow filename to be specified by OS Environment variable: PROC_PID_STATUS
+func GetFilename(pid int) string {
+	return procfs.Filename(getFilename(pid))
+}
+
+func asInt(check *procfs.Checker, field string, value string) int {
+	return check.Int(field, value)
+}
+
+func asInt64(check *procfs.Checker, field string, value string) int64 {
+	return check.Int64(field, value, 10)
+}
+
+func asUint64(check *procfs.Checker, field string, value string) uint64 {
+	return check.Uint64(field, value, 10)
+}
+
+func asHex(check *procfs.Checker, field string, value string) uint64 {
+	return check.Uint64(field, value, 16)
+}
+
+// A value such as "1392 kB" in bytes.
+func asBytes(check *procfs.Checker, field string, value string) uint64 {
+	splits := strings.Fields(value)
+	if !check.Fields(value, splits, 1) {
+		return uint64(0)
+	}
+	result := check.Uint64(field, splits[0], 10)
+	if len(splits) > 1 && splits[1] == "kB" {
+		result *= 1024
+	}
+	return result
+}
+
+// Four whitespace-separated IDs, as in the Uid and Gid fields.
+func asIDs(check *procfs.Checker, field string, value string) IDs {
+	splits := strings.Fields(value)
+	if !check.Fields(value, splits, 4) {
+		return IDs{}
+	}
+	return IDs{
+		Real:       uint32(check.Uint64(field, splits[0], 10)),
+		Effective:  uint32(check.Uint64(field, splits[1], 10)),
+		SavedSet:   uint32(check.Uint64(field, splits[2], 10)),
+		Filesystem: uint32(check.Uint64(field, splits[3], 10)),
+	}
+}
+
+func asInts(check *procfs.Checker, field string, value string) []int {
+	result := []int{}
+	for _, split := range strings.Fields(value) {
+		result = append(result, check.Int(field, split))
+	}
+	return result
+}
+
+func asUint32s(check *procfs.Checker, field string, value string) []uint32 {
+	result := []uint32{}
+	for _, split := range strings.Fields(value) {
+		result = append(result, uint32(check.Uint64(field, split, 10)))
+	}
+	return result
+}
+
+func Get(pid int) (Status, error) {
+	return GetFrom(procfs.Default, pid)
+}
+
+// Get values of [pid]/status from the procfs tree fsys.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     myStatus, err := status.GetFrom(myFS, 1)
+//     x := myStatus.Uid.Effective
+func GetFrom(fsys fs.FS, pid int) (Status, error) {
+
+	result := Status{}
+
+	// Read the file in a single read.
+
+	data, err := procfs.ReadFile(fsys, getFilename(pid))
+	if err != nil {
+		return result, err
+	}
+
+	// Parse the file.
+
+	check := procfs.NewChecker(fsys, getFilename(pid))
+	scanner := procfs.NewScanner(data)
+	for scanner.Scan() {
+		check.NextLine()
+		inputLine := scanner.Text()
+
+		// Split "key:<tab>value".
+
+		splits := strings.SplitN(inputLine, ":", 2)
+		if len(splits) != 2 {
+			if inputLine != "" {
+				check.Report("", inputLine, procfs.ErrShortLine)
+			}
+			continue
+		}
+		key := splits[0]
+		value := strings.TrimSpace(splits[1])
+
+		switch key {
+		case "Name":
+			result.Name = value
+		case "Umask":
+			result.Umask = uint32(check.Uint64(key, value, 8))
+		case "State":
+			result.State = strings.SplitN(value, " ", 2)[0]
+		case "Tgid":
+			result.Tgid = asInt(check, key, value)
+		case "Ngid":
+			result.Ngid = asInt(check, key, value)
+		case "Pid":
+			result.Pid = asInt(check, key, value)
+		case "PPid":
+			result.PPid = asInt(check, key, value)
+		case "TracerPid":
+			result.TracerPid = asInt(check, key, value)
+		case "Uid":
+			result.Uid = asIDs(check, key, value)
+		case "Gid":
+			result.Gid = asIDs(check, key, value)
+		case "FDSize":
+			result.FDSize = asUint64(check, key, value)
+		case "Groups":
+			result.Groups = asUint32s(check, key, value)
+		case "NStgid":
+			result.NStgid = asInts(check, key, value)
+		case "NSpid":
+			result.NSpid = asInts(check, key, value)
+		case "NSpgid":
+			result.NSpgid = asInts(check, key, value)
+		case "NSsid":
+			result.NSsid = asInts(check, key, value)
+		case "VmPeak":
+			result.VmPeak = asBytes(check, key, value)
+		case "VmSize":
+			result.VmSize = asBytes(check, key, value)
+		case "VmLck":
+			result.VmLck = asBytes(check, key, value)
+		case "VmPin":
+			result.VmPin = asBytes(check, key, value)
+		case "VmHWM":
+			result.VmHWM = asBytes(check, key, value)
+		case "VmRSS":
+			result.VmRSS = asBytes(check, key, value)
+		case "RssAnon":
+			result.RssAnon = asBytes(check, key, value)
+		case "RssFile":
+			result.RssFile = asBytes(check, key, value)
+		case "RssShmem":
+			result.RssShmem = asBytes(check, key, value)
+		case "VmData":
+			result.VmData = asBytes(check, key, value)
+		case "VmStk":
+			result.VmStk = asBytes(check, key, value)
+		case "VmExe":
+			result.VmExe = asBytes(check, key, value)
+		case "VmLib":
+			result.VmLib = asBytes(check, key, value)
+		case "VmPTE":
+			result.VmPTE = asBytes(check, key, value)
+		case "VmSwap":
+			result.VmSwap = asBytes(check, key, value)
+		case "HugetlbPages":
+			result.HugetlbPages = asBytes(check, key, value)
+		case "Threads":
+			result.Threads = asInt64(check, key, value)
+		case "SigPnd":
+			result.SigPnd = asHex(check, key, value)
+		case "ShdPnd":
+			result.ShdPnd = asHex(check, key, value)
+		case "SigBlk":
+			result.SigBlk = asHex(check, key, value)
+		case "SigIgn":
+			result.SigIgn = asHex(check, key, value)
+		case "SigCgt":
+			result.SigCgt = asHex(check, key, value)
+		case "CapInh":
+			result.CapInh = Capabilities(asHex(check, key, value))
+		case "CapPrm":
+			result.CapPrm = Capabilities(asHex(check, key, value))
+		case "CapEff":
+			result.CapEff = Capabilities(asHex(check, key, value))
+		case "CapBnd":
+			result.CapBnd = Capabilities(asHex(check, key, value))
+		case "CapAmb":
+			result.CapAmb = Capabilities(asHex(check, key, value))
+		case "NoNewPrivs":
+			result.NoNewPrivs = value == "1"
+		case "Seccomp":
+			result.Seccomp = SeccompMode(asInt(check, key, value))
+		case "Cpus_allowed_list":
+			result.Cpus_allowed_list = value
+		case "Mems_allowed_list":
+			result.Mems_allowed_list = value
+		case "voluntary_ctxt_switches":
+			result.Voluntary_ctxt_switches = asUint64(check, key, value)
+		case "nonvoluntary_ctxt_switches":
+			result.Nonvoluntary_ctxt_switches = asUint64(check, key, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, err
+	}
+	return result, check.Err()
+}
+
+func GetAsJson(pid int) ([]byte, error) {
+	return GetAsJsonFrom(procfs.Default, pid)
+}
+
+func GetAsJsonFrom(fsys fs.FS, pid int) ([]byte, error) {
+	content, err := GetFrom(fsys, pid)
+	if err != nil {
+		return []byte{}, err
+	}
+	return json.Marshal(content)
+}
+
+// Get values of /proc/[pid]/status as a map of interface{}.
+// Example:
+//     myStatus := status.GetAsMap(1)
+//     x := myStatus["VmRSS"]
+func GetAsMap(pid int) (map[string]interface{}, error) {
+	return GetAsMapFrom(procfs.Default, pid)
+}
+
+// Get values of [pid]/status from the procfs tree fsys as a map of interface{}.
+func GetAsMapFrom(fsys fs.FS, pid int) (map[string]interface{}, error) {
+
+	result := make(map[string]interface{})
+	status, err := GetFrom(fsys, pid)
+	if err != nil {
+		return result, err
+	}
+
+	result["Name"] = status.Name
+	result["Umask"] = status.Umask
+	result["State"] = status.State
+	result["Tgid"] = status.Tgid
+	result["Ngid"] = status.Ngid
+	result["Pid"] = status.Pid
+	result["PPid"] = status.PPid
+	result["TracerPid"] = status.TracerPid
+	result["Uid"] = status.Uid
+	result["Gid"] = status.Gid
+	result["FDSize"] = status.FDSize
+	result["Groups"] = status.Groups
+	result["NStgid"] = status.NStgid
+	result["NSpid"] = status.NSpid
+	result["NSpgid"] = status.NSpgid
+	result["NSsid"] = status.NSsid
+	result["VmPeak"] = status.VmPeak
+	result["VmSize"] = status.VmSize
+	result["VmLck"] = status.VmLck
+	result["VmPin"] = status.VmPin
+	result["VmHWM"] = status.VmHWM
+	result["VmRSS"] = status.VmRSS
+	result["RssAnon"] = status.RssAnon
+	result["RssFile"] = status.RssFile
+	result["RssShmem"] = status.RssShmem
+	result["VmData"] = status.VmData
+	result["VmStk"] = status.VmStk
+	result["VmExe"] = status.VmExe
+	result["VmLib"] = status.VmLib
+	result["VmPTE"] = status.VmPTE
+	result["VmSwap"] = status.VmSwap
+	result["HugetlbPages"] = status.HugetlbPages
+	result["Threads"] = status.Threads
+	result["SigPnd"] = status.SigPnd
+	result["ShdPnd"] = status.ShdPnd
+	result["SigBlk"] = status.SigBlk
+	result["SigIgn"] = status.SigIgn
+	result["SigCgt"] = status.SigCgt
+	result["CapInh"] = status.CapInh
+	result["CapPrm"] = status.CapPrm
+	result["CapEff"] = status.CapEff
+	result["CapBnd"] = status.CapBnd
+	result["CapAmb"] = status.CapAmb
+	result["NoNewPrivs"] = status.NoNewPrivs
+	result["Seccomp"] = status.Seccomp
+	result["Cpus_allowed_list"] = status.Cpus_allowed_list
+	result["Mems_allowed_list"] = status.Mems_allowed_list
+	result["voluntary_ctxt_switches"] = status.Voluntary_ctxt_switches
+	result["nonvoluntary_ctxt_switches"] = status.Nonvoluntary_ctxt_switches
+
+	return result, nil
+}
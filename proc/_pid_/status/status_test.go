@@ -0,0 +1,95 @@
+package status
+
+import (
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"io/fs"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// The trees under testdata/, read from an fs.FS and, through PROC_ROOT, from
+// the default procfs tree.
+func TestGolden(t *testing.T) {
+	for _, tree := range golden.Trees(t) {
+		t.Run(tree.Name, func(t *testing.T) {
+			content, err := GetFrom(tree.FS, tree.Pid)
+			golden.Check(t, tree, "pid_status", content, err)
+		})
+		t.Run(tree.Name+"/PROC_ROOT", func(t *testing.T) {
+			tree.SetRoot(t)
+			content, err := Get(tree.Pid)
+			golden.Check(t, tree, "pid_status", content, err)
+		})
+	}
+}
+
+// Single values, with memory in bytes and capabilities as bit sets.
+func TestGetFrom(t *testing.T) {
+	tree := golden.Find(t, "linux-6.18-container-x86_64")
+	status, err := GetFrom(tree.FS, tree.Pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Name != "process_api" || status.State != "S" || status.Tgid != 1 || status.Threads != 6 {
+		t.Errorf("got Name %q, State %q, Tgid %d, Threads %d", status.Name, status.State, status.Tgid, status.Threads)
+	}
+	if status.VmRSS != 9388*1024 {
+		t.Errorf("VmRSS: got %d, want %d", status.VmRSS, 9388*1024)
+	}
+	if status.CapEff != 0x1ffffffffff || !status.CapEff.Has(40) || status.CapEff.Has(41) {
+		t.Errorf("CapEff: got %#x", uint64(status.CapEff))
+	}
+	if len(status.Groups) != 0 {
+		t.Errorf("Groups: got %v, want none", status.Groups)
+	}
+}
+
+// Malformed lines give a ParseError in strict mode and a warning in lenient
+// mode; the other lines are still read.
+func TestGetFromMalformed(t *testing.T) {
+	tests := []golden.Malformed{
+		{
+			Name:     "value",
+			Data:     "Name:\tinit\nVmRSS:\t    93x8 kB\nThreads:\t1\n",
+			Expected: Status{Name: "init", Threads: 1},
+			Wants:    []procfs.ParseError{{File: "1/status", Line: 2, Field: "VmRSS", Text: "93x8", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "too few IDs",
+			Data:     "Uid:\t0\t0\t0\nGid:\t5\t5\t5\t5\n",
+			Expected: Status{Gid: IDs{5, 5, 5, 5}},
+			Wants:    []procfs.ParseError{{File: "1/status", Line: 1, Text: "0\t0\t0", Err: procfs.ErrShortLine}},
+		},
+		{
+			Name:     "no colon",
+			Data:     "Name init\nTgid:\t1\n",
+			Expected: Status{Tgid: 1},
+			Wants:    []procfs.ParseError{{File: "1/status", Line: 1, Text: "Name init", Err: procfs.ErrShortLine}},
+		},
+		{
+			Name:     "capabilities",
+			Data:     "CapEff:\t000001fffffffffg\nSeccomp:\t2\n",
+			Expected: Status{Seccomp: SeccompFilter},
+			Wants:    []procfs.ParseError{{File: "1/status", Line: 1, Field: "CapEff", Text: "000001fffffffffg", Err: strconv.ErrSyntax}},
+		},
+	}
+	for _, test := range tests {
+		golden.CheckMalformed(t, "1/status", test, func(fsys fs.FS) (interface{}, error) { return GetFrom(fsys, 1) })
+	}
+}
+
+// Parse any [pid]/status without panicking, into a result or a ParseError.
+func FuzzGetFrom(f *testing.F) {
+	golden.Seed(f, "[pid]/status")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, fsys := range golden.Modes(fstest.MapFS{"1/status": {Data: data}}) {
+			_, err := GetFrom(fsys, 1)
+			golden.CheckError(t, err)
+			_, err = GetAsMapFrom(fsys, 1)
+			golden.CheckError(t, err)
+		}
+	})
+}
@@ -0,0 +1,384 @@
+package proc
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+
+	"github.com/docktermj/go-proc-parse/proc/_pid_/fd"
+	"github.com/docktermj/go-proc-parse/proc/_pid_/stat"
+	"github.com/docktermj/go-proc-parse/proc/_pid_/status"
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"github.com/docktermj/go-proc-parse/proc/loadavg"
+	"github.com/docktermj/go-proc-parse/proc/meminfo"
+	"github.com/docktermj/go-proc-parse/proc/net/dev"
+	"github.com/docktermj/go-proc-parse/proc/net/netstat"
+	"github.com/docktermj/go-proc-parse/proc/net/snmp"
+	"github.com/docktermj/go-proc-parse/proc/net/tcp"
+	sysstat "github.com/docktermj/go-proc-parse/proc/stat"
+	"github.com/docktermj/go-proc-parse/proc/uptime"
+)
+
+// A file compared by Compare, known by the name of its schema and read with
+// the GetAsMapFrom function of its package.
+type mapFile struct {
+	name       string
+	perProcess bool
+	getMap     func(fsys fs.FS, pid int) (interface{}, error)
+}
+
+func newMapFile[T any](name string, getMap func(fs.FS) (T, error)) mapFile {
+	return mapFile{
+		name:   name,
+		getMap: func(fsys fs.FS, pid int) (interface{}, error) { return getMap(fsys) },
+	}
+}
+
+// A file in the format of /proc/net/tcp, e.g. tcp.Udp.
+func newSocketMapFile(name string, socketFile string) mapFile {
+	return newMapFile(name, func(fsys fs.FS) (map[string]map[string]interface{}, error) {
+		return tcp.GetAsMapFrom(fsys, socketFile)
+	})
+}
+
+// A file in the directory of a process.
+func newPidMapFile[T any](name string, getMap func(fs.FS, int) (T, error)) mapFile {
+	return mapFile{
+		name:       name,
+		perProcess: true,
+		getMap:     func(fsys fs.FS, pid int) (interface{}, error) { return getMap(fsys, pid) },
+	}
+}
+
+var mapFiles = []mapFile{
+	newMapFile("loadavg", loadavg.GetAsMapFrom),
+	newMapFile("meminfo", meminfo.GetAsMapFrom),
+	newMapFile("net_dev", dev.GetAsMapFrom),
+	newMapFile("net_netstat", netstat.GetAsMapFrom),
+	newSocketMapFile("net_raw", tcp.Raw),
+	newSocketMapFile("net_raw6", tcp.Raw6),
+	newMapFile("net_snmp", snmp.GetAsMapFrom),
+	newSocketMapFile("net_tcp", tcp.Tcp),
+	newSocketMapFile("net_tcp6", tcp.Tcp6),
+	newSocketMapFile("net_udp", tcp.Udp),
+	newSocketMapFile("net_udp6", tcp.Udp6),
+	newPidMapFile("pid_fd", fd.GetAsMapFrom),
+	newPidMapFile("pid_stat", stat.GetAsMapFrom),
+	newPidMapFile("pid_status", status.GetAsMapFrom),
+	newMapFile("stat", sysstat.GetAsMapFrom),
+	newMapFile("uptime", uptime.GetAsMapFrom),
+}
+
+// Names of the files that Compare can compare, in alphabetical order.
+func DiffNames() []string {
+	result := []string{}
+	for _, aFile := range mapFiles {
+		result = append(result, aFile.name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// A value that differs between two procfs trees.  Key is the path of the
+// value in the GetAsMap of File, joined by "/", e.g. "Tcp/InSegs" in
+// "net_snmp" or "eth0/ReceiveDrop" in "net_dev".  Pid is set for the files
+// of a process.  Old or New is nil where the key is only in the other tree,
+// e.g. for a new socket.  Delta is New minus Old for numbers, and nil for
+// other values.
+type Change struct {
+	File  string      `json:"file"`
+	Pid   int         `json:"pid,omitempty"`
+	Key   string      `json:"key"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+	Delta interface{} `json:"delta,omitempty"`
+}
+
+// A process, told apart from a later one with the same pid by Starttime.
+// Comm may change, e.g. for kernel threads, without the process changing.
+type Process struct {
+	Pid       int    `json:"pid"`
+	Comm      string `json:"comm"`
+	Starttime uint64 `json:"starttime"`
+}
+
+// Differences between two procfs trees, as returned by Compare.  A pid that
+// was reused between the trees is both in Exited and in Appeared; both are
+// empty unless a file of a process, e.g. "pid_stat", is compared.  Skipped
+// names the files that could not be read in one of the trees, e.g. net/raw6
+// without IPv6.
+type Diff struct {
+	Changes  []Change  `json:"changes"`
+	Appeared []Process `json:"appeared"`
+	Exited   []Process `json:"exited"`
+	Skipped  []string  `json:"skipped"`
+}
+
+// Compare the files names, e.g. "net_snmp", of the procfs trees a and b, or
+// every file in DiffNames if names is empty.  The files of a process are
+// compared for the processes in both trees; files a process does not let us
+// read are left out.
+// Example:
+//     myDiff, err := proc.Compare(myBefore, myAfter, "net_snmp", "pid_stat")
+//     for _, myChange := range myDiff.Changes {
+//         fmt.Println(myChange.File, myChange.Pid, myChange.Key, myChange.Delta)
+//     }
+func Compare(a FS, b FS, names ...string) (Diff, error) {
+
+	result := Diff{
+		Changes:  []Change{},
+		Appeared: []Process{},
+		Exited:   []Process{},
+		Skipped:  []string{},
+	}
+
+	selected := mapFiles
+	if len(names) > 0 {
+		selected = []mapFile{}
+		for _, name := range names {
+			aFile, ok := findMapFile(name)
+			if !ok {
+				return result, fmt.Errorf("proc: cannot compare unknown file %q", name)
+			}
+			selected = append(selected, aFile)
+		}
+	}
+
+	// Processes that appeared, exited or are in both trees, if a file of a
+	// process is compared.
+
+	perProcess := false
+	for _, aFile := range selected {
+		perProcess = perProcess || aFile.perProcess
+	}
+	processesA, processesB := map[int]Process{}, map[int]Process{}
+	if perProcess {
+		var err error
+		processesA, err = processes(a)
+		if err != nil {
+			return result, err
+		}
+		processesB, err = processes(b)
+		if err != nil {
+			return result, err
+		}
+	}
+	common := []int{}
+	for pid, process := range processesA {
+		if other, ok := processesB[pid]; ok && other.Starttime == process.Starttime {
+			common = append(common, pid)
+		} else {
+			result.Exited = append(result.Exited, process)
+		}
+	}
+	for pid, process := range processesB {
+		if other, ok := processesA[pid]; !ok || other.Starttime != process.Starttime {
+			result.Appeared = append(result.Appeared, process)
+		}
+	}
+	sort.Ints(common)
+	sort.Slice(result.Exited, func(i, j int) bool { return result.Exited[i].Pid < result.Exited[j].Pid })
+	sort.Slice(result.Appeared, func(i, j int) bool { return result.Appeared[i].Pid < result.Appeared[j].Pid })
+
+	// Values of the files.
+
+	for _, aFile := range selected {
+		if !aFile.perProcess {
+			changes, err := compareFile(a, b, aFile, 0)
+			if isSkipped(err) {
+				result.Skipped = append(result.Skipped, aFile.name)
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+			result.Changes = append(result.Changes, changes...)
+			continue
+		}
+		for _, pid := range common {
+			changes, err := compareFile(a, b, aFile, pid)
+			if isSkipped(err) {
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+			result.Changes = append(result.Changes, changes...)
+		}
+	}
+	return result, nil
+}
+
+func findMapFile(name string) (mapFile, bool) {
+	for _, aFile := range mapFiles {
+		if aFile.name == name {
+			return aFile, true
+		}
+	}
+	return mapFile{}, false
+}
+
+// Every process of the procfs tree f, by pid.
+func processes(f FS) (map[int]Process, error) {
+	result := make(map[int]Process)
+	pids, err := f.Pids()
+	if err != nil {
+		return result, err
+	}
+	for _, pid := range pids {
+		aStat, err := f.Stat(pid)
+		if isSkipped(err) {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result[pid] = Process{Pid: pid, Comm: aStat.Comm, Starttime: aStat.Starttime}
+	}
+	return result, nil
+}
+
+// Changes of aFile of process pid between the procfs trees a and b, in the
+// order of their keys.
+func compareFile(a FS, b FS, aFile mapFile, pid int) ([]Change, error) {
+	result := []Change{}
+	valuesA, err := flattenFile(a, aFile, pid)
+	if err != nil {
+		return result, err
+	}
+	valuesB, err := flattenFile(b, aFile, pid)
+	if err != nil {
+		return result, err
+	}
+
+	keys := []string{}
+	for key := range valuesA {
+		keys = append(keys, key)
+	}
+	for key := range valuesB {
+		if _, ok := valuesA[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		before, after := valuesA[key], valuesB[key]
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		result = append(result, Change{
+			File:  aFile.name,
+			Pid:   pid,
+			Key:   key,
+			Old:   before,
+			New:   after,
+			Delta: delta(before, after),
+		})
+	}
+	return result, nil
+}
+
+// The values of aFile of process pid in the procfs tree f, keyed by their
+// path.
+func flattenFile(f FS, aFile mapFile, pid int) (map[string]interface{}, error) {
+	content, err := aFile.getMap(f.fsys, pid)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]interface{})
+	err = flatten(result, "", reflect.ValueOf(content))
+	return result, err
+}
+
+// Add the values of value to result, keyed by their path under prefix.
+// Numbers are held as int64, uint64 or float64.  Structs are keyed like their
+// JSON, and values that write their own JSON, e.g. tcp.State, are not taken
+// apart.
+func flatten(result map[string]interface{}, prefix string, value reflect.Value) error {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "/" + key
+	}
+
+	if !value.IsValid() {
+		result[prefix] = nil
+		return nil
+	}
+	if procfs.IsMarshaler(value.Type()) {
+		result[prefix] = value.Interface()
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			result[prefix] = nil
+			return nil
+		}
+		return flatten(result, prefix, value.Elem())
+	case reflect.Struct:
+		for index := 0; index < value.NumField(); index++ {
+			key, _, ok := procfs.FieldKey(value.Type().Field(index))
+			if !ok {
+				continue
+			}
+			if err := flatten(result, join(key), value.Field(index)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iterator := value.MapRange()
+		for iterator.Next() {
+			key, err := procfs.MapKey(iterator.Key())
+			if err != nil {
+				return err
+			}
+			if err := flatten(result, join(key), iterator.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for index := 0; index < value.Len(); index++ {
+			if err := flatten(result, join(fmt.Sprint(index)), value.Index(index)); err != nil {
+				return err
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		result[prefix] = value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		result[prefix] = value.Uint()
+	case reflect.Float32, reflect.Float64:
+		result[prefix] = value.Float()
+	case reflect.String:
+		result[prefix] = value.String()
+	case reflect.Bool:
+		result[prefix] = value.Bool()
+	default:
+		return fmt.Errorf("proc: cannot compare values of type %s", value.Type())
+	}
+	return nil
+}
+
+// after minus before if both are numbers of the same kind, or nil.  Counters
+// are subtracted as integers, so the delta is exact however large they are.
+func delta(before interface{}, after interface{}) interface{} {
+	switch before := before.(type) {
+	case int64:
+		if after, ok := after.(int64); ok {
+			return after - before
+		}
+	case uint64:
+		if after, ok := after.(uint64); ok {
+			return int64(after - before)
+		}
+	case float64:
+		if after, ok := after.(float64); ok {
+			return after - before
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package proc
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// Two procfs trees: MemFree goes down, MemAvailable is new, Tcp MaxConn goes
+// from -1 to a limit, loadavg is only in the first, and net/dev is in neither.  1 runs on, the pid 2 is reused,
+// 3 exits and 4 starts.
+func diffTrees() (FS, FS) {
+	a := fstest.MapFS{
+		"meminfo":  {Data: []byte("MemTotal:        1000 kB\nMemFree:          600 kB\n")},
+		"uptime":   {Data: []byte("100.00 150.25\n")},
+		"loadavg":  {Data: []byte("0.50 0.40 0.30 1/100 1234\n")},
+		"net/snmp": {Data: []byte("Tcp: MaxConn InSegs\nTcp: -1 100\n")},
+		"1/stat":   {Data: []byte(pidStat{pid: 1, starttime: 7, utime: 60, stime: 40, minflt: 1000, rss: 2350}.String())},
+		"2/stat":   {Data: []byte(pidStat{pid: 2, starttime: 500}.String())},
+		"3/stat":   {Data: []byte(pidStat{pid: 3, starttime: 600}.String())},
+	}
+	b := fstest.MapFS{
+		"meminfo":  {Data: []byte("MemTotal:        1000 kB\nMemFree:          400 kB\nMemAvailable:     500 kB\n")},
+		"uptime":   {Data: []byte("160.50 250.25\n")},
+		"net/snmp": {Data: []byte("Tcp: MaxConn InSegs\nTcp: 200 130\n")},
+		"1/stat":   {Data: []byte(pidStat{pid: 1, starttime: 7, utime: 90, stime: 40, minflt: 1600, rss: 2300}.String())},
+		"2/stat":   {Data: []byte(pidStat{pid: 2, starttime: 900}.String())},
+		"4/stat":   {Data: []byte(pidStat{pid: 4, starttime: 950}.String())},
+	}
+	return NewFSFromFS(a), NewFSFromFS(b)
+}
+
+func TestCompare(t *testing.T) {
+	a, b := diffTrees()
+	actual, err := Compare(a, b, "meminfo", "uptime", "loadavg", "net_dev", "net_snmp", "pid_stat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Diff{
+		Changes: []Change{
+			{File: "meminfo", Key: "MemAvailable", Old: nil, New: uint64(500)},
+			{File: "meminfo", Key: "MemFree", Old: uint64(600), New: uint64(400), Delta: int64(-200)},
+			{File: "uptime", Key: "idle", Old: 150.25, New: 250.25, Delta: 100.0},
+			{File: "uptime", Key: "uptime", Old: 100.0, New: 160.5, Delta: 60.5},
+			{File: "net_snmp", Key: "Tcp/InSegs", Old: uint64(100), New: uint64(130), Delta: int64(30)},
+			{File: "net_snmp", Key: "Tcp/MaxConn", Old: int64(-1), New: int64(200), Delta: int64(201)},
+			{File: "pid_stat", Pid: 1, Key: "minflt", Old: uint64(1000), New: uint64(1600), Delta: int64(600)},
+			{File: "pid_stat", Pid: 1, Key: "rss", Old: int64(2350), New: int64(2300), Delta: int64(-50)},
+			{File: "pid_stat", Pid: 1, Key: "utime", Old: uint64(60), New: uint64(90), Delta: int64(30)},
+		},
+		Appeared: []Process{{Pid: 2, Comm: "p2", Starttime: 900}, {Pid: 4, Comm: "p4", Starttime: 950}},
+		Exited:   []Process{{Pid: 2, Comm: "p2", Starttime: 500}, {Pid: 3, Comm: "p3", Starttime: 600}},
+		Skipped:  []string{"loadavg", "net_dev"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Compare =\n%+v\nwant\n%+v", actual, expected)
+	}
+}
+
+// Processes are only read if a file of a process is compared, so a broken
+// [pid]/stat fails only those comparisons.
+func TestCompareProcesses(t *testing.T) {
+	a, _ := diffTrees()
+	b := NewFSFromFS(fstest.MapFS{
+		"uptime": {Data: []byte("160.50 250.25\n")},
+		"5/stat": {Data: []byte("5 (broken) S x\n")},
+	})
+	actual, err := Compare(a, b, "uptime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual.Changes) != 2 || len(actual.Appeared) != 0 || len(actual.Exited) != 0 {
+		t.Errorf("Compare of uptime = %+v", actual)
+	}
+	if _, err := Compare(a, b, "uptime", "pid_stat"); err == nil {
+		t.Error("Compare of pid_stat with a broken 5/stat: no error")
+	}
+}
+
+func TestCompareUnknownName(t *testing.T) {
+	a, b := diffTrees()
+	if _, err := Compare(a, b, "meminfo", "nope"); err == nil {
+		t.Error("Compare of an unknown file: no error")
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	type inner struct {
+		Value  int64   `json:"value"`
+		Hidden string  `json:"-"`
+		Ratio  float64 `json:"Ratio"`
+	}
+	content := map[string]interface{}{
+		"list":   []inner{{Value: -1, Ratio: 0.5}},
+		"nested": map[int]uint32{7: 8},
+		"none":   nil,
+		"ok":     true,
+	}
+	actual := map[string]interface{}{}
+	if err := flatten(actual, "", reflect.ValueOf(content)); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"list/0/value": int64(-1),
+		"list/0/Ratio": 0.5,
+		"nested/7":     uint64(8),
+		"none":         nil,
+		"ok":           true,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("flatten = %v; want %v", actual, expected)
+	}
+
+	for _, unsupported := range []interface{}{
+		map[string]chan int{"c": make(chan int)},
+		[]complex128{1i},
+		struct {
+			F func() `json:"f"`
+		}{func() {}},
+	} {
+		if err := flatten(map[string]interface{}{}, "", reflect.ValueOf(unsupported)); err == nil {
+			t.Errorf("flatten(%T): no error", unsupported)
+		}
+	}
+}
+
+func TestDiffDelta(t *testing.T) {
+	tests := []struct {
+		before, after interface{}
+		expected      interface{}
+	}{
+		{uint64(10), uint64(15), int64(5)},
+		{uint64(15), uint64(10), int64(-5)},
+		{uint64(1 << 63), uint64(1<<63 + 1), int64(1)}, // Exact beyond float64.
+		{int64(-1), int64(2), int64(3)},
+		{1.5, 1.0, -0.5},
+		{uint64(1), int64(2), nil},
+		{nil, uint64(2), nil},
+		{"a", "b", nil},
+	}
+	for _, test := range tests {
+		if actual := delta(test.before, test.after); actual != test.expected {
+			t.Errorf("delta(%v, %v) = %v; want %v", test.before, test.after, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package proc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A file, or view of one, known by the name of its schema.
+type file struct {
+	name        string
+	description string
+	valueType   reflect.Type
+	get         func(f FS, pid int) (interface{}, error)
+}
+
+func newFile[T any](name string, description string, get func(FS) (T, error)) file {
+	return file{
+		name:        name,
+		description: description,
+		valueType:   reflect.TypeOf((*T)(nil)).Elem(),
+		get:         func(f FS, pid int) (interface{}, error) { return get(f) },
+	}
+}
+
+// A file in the directory of a process.
+func newPidFile[T any](name string, description string, get func(FS, int) (T, error)) file {
+	return file{
+		name:        name,
+		description: description,
+		valueType:   reflect.TypeOf((*T)(nil)).Elem(),
+		get:         func(f FS, pid int) (interface{}, error) { return get(f, pid) },
+	}
+}
+
+var files = []file{
+	newFile("loadavg", "/proc/loadavg as written by loadavg.GetAsJson.", FS.Loadavg),
+	newFile("meminfo", "/proc/meminfo as written by meminfo.GetAsJson.  Values are in kB except for the HugePages_* counts.", FS.Meminfo),
+	newFile("meminfo_summary", "Summary of /proc/meminfo in bytes, as returned by meminfo.GetSummary.", FS.MeminfoSummary),
+	newFile("net_dev", "/proc/net/dev as written by dev.GetAsJson, keyed by interface.", FS.NetDev),
+	newFile("net_netstat", "/proc/net/netstat as written by netstat.GetAsJson.", FS.NetNetstat),
+	newFile("net_raw", "/proc/net/raw as written by tcp.GetAsJson.", FS.NetRaw),
+	newFile("net_raw6", "/proc/net/raw6 as written by tcp.GetAsJson.", FS.NetRaw6),
+	newFile("net_snmp", "/proc/net/snmp as written by snmp.GetAsJson.", FS.NetSnmp),
+	newFile("net_tcp", "/proc/net/tcp as written by tcp.GetAsJson.", FS.NetTcp),
+	newFile("net_tcp6", "/proc/net/tcp6 as written by tcp.GetAsJson.", FS.NetTcp6),
+	newFile("net_udp", "/proc/net/udp as written by tcp.GetAsJson.", FS.NetUdp),
+	newFile("net_udp6", "/proc/net/udp6 as written by tcp.GetAsJson.", FS.NetUdp6),
+	newPidFile("pid_fd", "/proc/[pid]/fd as written by fd.GetAsJson.", FS.Fd),
+	newFile("pid_fd_index", "Owners of sockets, pipes and anonymous inodes, as returned by fd.GetIndex.", FS.FdIndex),
+	newPidFile("pid_stat", "/proc/[pid]/stat as written by stat.GetAsJson.", FS.Stat),
+	newPidFile("pid_status", "/proc/[pid]/status as written by status.GetAsJson.", FS.Status),
+	newFile("stat", "/proc/stat as written by stat.GetAsJson of package proc/stat.", FS.SystemStat),
+	newFile("uptime", "/proc/uptime as written by uptime.GetAsJson.  Durations are in nanoseconds.", FS.Uptime),
+}
+
+func findFile(name string) (file, bool) {
+	for _, aFile := range files {
+		if aFile.name == name {
+			return aFile, true
+		}
+	}
+	return file{}, false
+}
+
+// Get the values of a file by the name of its schema, e.g. "net_dev".  pid is
+// only used for the files of a process, e.g. "pid_stat".
+// Example:
+//     x, err := proc.Default().Get("meminfo", 0)
+//     y, err := proc.MarshalJSON(x, proc.SnakeCase)
+func (f FS) Get(name string, pid int) (interface{}, error) {
+	aFile, ok := findFile(name)
+	if !ok {
+		return nil, fmt.Errorf("proc: unknown file %q", name)
+	}
+	return aFile.get(f, pid)
+}
@@ -0,0 +1,273 @@
+// Package golden compares what the parsers read from the procfs trees under
+// testdata/ with the JSON in the golden/ directory of each tree, and seeds
+// the fuzz tests of the parsers with the files of the trees.  It is only
+// imported by tests.
+// Example:
+//     func TestGolden(t *testing.T) {
+//         for _, tree := range golden.Trees(t) {
+//             t.Run(tree.Name, func(t *testing.T) {
+//                 myMeminfo, err := GetFrom(tree.FS)
+//                 golden.Check(t, tree, "meminfo", myMeminfo, err)
+//             })
+//         }
+//     }
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// Rewrite the golden files instead of comparing with them if the OS
+// Environment variable UPDATE_GOLDEN is set, e.g.
+//     UPDATE_GOLDEN=1 go test ./proc/...
+// A flag would be unknown to the tests of packages that do not import
+// golden.
+var update = os.Getenv("UPDATE_GOLDEN") != ""
+
+// A procfs tree under testdata/, read in strict mode: a capture, named
+// "linux-*", or a tree written by hand, named "synthetic-linux-*".  Pid is the
+// process whose directory the tree holds.
+type Tree struct {
+	Name string
+	Dir  string
+	Pid  int
+	FS   fs.FS
+}
+
+// Names of the procfs trees under testdata/.
+const treePattern = "*linux-*"
+
+// The directory testdata/ of the repository, found from the directory of
+// the test.
+func testdata(t testing.TB) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		candidate := filepath.Join(dir, "testdata")
+		if matches, _ := filepath.Glob(filepath.Join(candidate, treePattern)); len(matches) > 0 {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatal("golden: no procfs tree under testdata/ above the directory of the test")
+		}
+		dir = parent
+	}
+}
+
+// Trees returns the procfs trees under testdata/, in the order of their
+// names.
+func Trees(t testing.TB) []Tree {
+	t.Helper()
+	dirs, err := filepath.Glob(filepath.Join(testdata(t), treePattern))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := []Tree{}
+	for _, dir := range dirs {
+		aTree := Tree{
+			Name: filepath.Base(dir),
+			Dir:  dir,
+			FS:   procfs.Strict(os.DirFS(dir)),
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range entries {
+			if pid, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+				aTree.Pid = pid
+			}
+		}
+		result = append(result, aTree)
+	}
+	return result
+}
+
+// Find returns the tree of Trees named name, e.g.
+// "linux-6.18-container-x86_64", for tests that check single values of it.
+func Find(t testing.TB, name string) Tree {
+	t.Helper()
+	for _, tree := range Trees(t) {
+		if tree.Name == name {
+			return tree
+		}
+	}
+	t.Fatalf("golden: no procfs tree %s under testdata/", name)
+	return Tree{}
+}
+
+// SetRoot points the default procfs tree at tree for the rest of t, through
+// the OS Environment variable PROC_ROOT, so that the Get functions read it.
+// Other PROC_* variables, which redirect single files, are cleared.  The
+// default tree is not strict, which makes no difference for the files of
+// testdata/.
+func (tree Tree) SetRoot(t *testing.T) {
+	t.Helper()
+	for _, variable := range os.Environ() {
+		if name, _, _ := strings.Cut(variable, "="); strings.HasPrefix(name, "PROC_") {
+			t.Setenv(name, "")
+		}
+	}
+	t.Setenv("PROC_ROOT", tree.Dir)
+}
+
+// Path of the golden file of name, e.g. "net_dev", in tree.
+func (tree Tree) Golden(name string) string {
+	return filepath.Join(tree.Dir, "golden", name+".json")
+}
+
+// Check compares content, as the JSON that "go-proc-parse json" writes, with
+// the golden file of name in tree, or writes the golden file if UPDATE_GOLDEN
+// is set.
+// err is the error of reading content; a file the tree does not hold must
+// have no golden file.
+func Check(t *testing.T, tree Tree, name string, content interface{}, err error) {
+	t.Helper()
+	golden := tree.Golden(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		if _, statErr := os.Stat(golden); statErr == nil {
+			t.Fatalf("%s: %v", golden, err)
+		}
+		t.Skipf("%s has no %s", tree.Name, name)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compact, err := procfs.MarshalJSON(content, procfs.KernelCase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := bytes.Buffer{}
+	if err := json.Indent(&actual, compact, "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	actual.WriteByte('\n')
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, actual.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v; if the output is intended, run: UPDATE_GOLDEN=1 go test ./proc/...", err)
+	}
+	if !bytes.Equal(actual.Bytes(), expected) {
+		t.Errorf("%s differs; if the change is intended, run: UPDATE_GOLDEN=1 go test ./proc/...\ngot:\n%s", golden, actual.Bytes())
+	}
+}
+
+// Seed adds the file name, e.g. "net/dev" or "[pid]/stat", of every tree that
+// holds it to the seed corpus of f.
+func Seed(f *testing.F, name string) {
+	f.Helper()
+	for _, tree := range Trees(f) {
+		path := strings.ReplaceAll(name, "[pid]", strconv.Itoa(tree.Pid))
+		data, err := os.ReadFile(filepath.Join(tree.Dir, filepath.FromSlash(path)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+}
+
+// Modes returns fsys in strict and in lenient mode, the modes a parser is
+// fuzzed in.
+func Modes(fsys fs.FS) []fs.FS {
+	return []fs.FS{procfs.Strict(fsys), procfs.Lenient(fsys, &procfs.Warnings{})}
+}
+
+// CheckError fails t unless err is nil, a *procfs.ParseError, or an error
+// that errors.As finds in one of targets, e.g. a **stat.TruncatedError.
+func CheckError(t *testing.T, err error, targets ...interface{}) {
+	t.Helper()
+	var parseError *procfs.ParseError
+	if err == nil || errors.As(err, &parseError) {
+		return
+	}
+	for _, target := range targets {
+		if errors.As(err, target) {
+			return
+		}
+	}
+	t.Errorf("untyped error: %v", err)
+}
+
+// A test of a malformed file: Name describes it, Data is the file, Expected
+// the value parsed in spite of it, and Wants the ParseErrors it gives, in
+// order.
+type Malformed struct {
+	Name     string
+	Data     string
+	Expected interface{}
+	Wants    []procfs.ParseError
+}
+
+// CheckMalformed parses test.Data as the file name of a procfs tree with
+// parse, in strict and in lenient mode.  Strict mode must return the first of
+// test.Wants, lenient mode no error and test.Wants as its warnings, and both
+// test.Expected.  The Err of a ParseError is compared with errors.Is.
+func CheckMalformed(t *testing.T, name string, test Malformed, parse func(fsys fs.FS) (interface{}, error)) {
+	t.Helper()
+	tree := fstest.MapFS{name: {Data: []byte(test.Data)}}
+
+	result, err := parse(procfs.Strict(tree))
+	var parseError *procfs.ParseError
+	if !errors.As(err, &parseError) {
+		t.Errorf("%s: strict: got %v, want a ParseError", test.Name, err)
+	} else if len(test.Wants) == 0 || !sameParseError(*parseError, test.Wants[0]) {
+		t.Errorf("%s: strict: got %#v, want %#v", test.Name, *parseError, test.Wants)
+	}
+	if !reflect.DeepEqual(result, test.Expected) {
+		t.Errorf("%s: strict: got %+v, want %+v", test.Name, result, test.Expected)
+	}
+
+	warnings := procfs.Warnings{}
+	result, err = parse(procfs.Lenient(tree, &warnings))
+	if err != nil {
+		t.Errorf("%s: lenient: %v", test.Name, err)
+	}
+	warned := warnings.Errors()
+	same := len(warned) == len(test.Wants)
+	for index := 0; same && index < len(warned); index++ {
+		same = sameParseError(*warned[index], test.Wants[index])
+	}
+	if !same {
+		t.Errorf("%s: lenient: got warnings %v, want %#v", test.Name, warned, test.Wants)
+	}
+	if !reflect.DeepEqual(result, test.Expected) {
+		t.Errorf("%s: lenient: got %+v, want %+v", test.Name, result, test.Expected)
+	}
+}
+
+func sameParseError(actual procfs.ParseError, expected procfs.ParseError) bool {
+	return actual.File == expected.File &&
+		actual.Line == expected.Line &&
+		actual.Field == expected.Field &&
+		actual.Text == expected.Text &&
+		errors.Is(actual.Err, expected.Err)
+}
@@ -0,0 +1,253 @@
+package procfs
+
+import (
+	"errors"
+	"io/fs"
+	"strconv"
+	"sync"
+)
+
+// ErrShortLine is the error of a ParseError for a line with fewer fields than
+// the file format has.
+var ErrShortLine = errors.New("too few fields")
+
+// ErrHeaderMismatch is the error of a ParseError for a line of values that
+// does not match the header line before it, e.g. in net/snmp.
+var ErrHeaderMismatch = errors.New("values do not match header")
+
+// A ParseError reports a value in a procfs file that could not be parsed.
+// File is relative to the procfs root and Line counts from 1.  Field is the
+// name of the value, or empty if the line as a whole is malformed, in which
+// case Text is the whole line.
+type ParseError struct {
+	File  string
+	Line  int
+	Field string
+	Text  string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	result := "proc: " + e.File + ":" + strconv.Itoa(e.Line) + ": "
+	if e.Field != "" {
+		result += e.Field + ": "
+	}
+	return result + strconv.Quote(e.Text) + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Warnings collects the ParseErrors of a lenient procfs tree.  It is safe for
+// concurrent use.
+type Warnings struct {
+	mutex  sync.Mutex
+	errors []*ParseError
+}
+
+func (w *Warnings) add(err *ParseError) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.errors = append(w.errors, err)
+}
+
+// The ParseErrors collected so far, oldest first.
+func (w *Warnings) Errors() []*ParseError {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return append([]*ParseError(nil), w.errors...)
+}
+
+// Forget the ParseErrors collected so far, e.g. between two polls.
+func (w *Warnings) Reset() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.errors = nil
+}
+
+// How parsers handle a value that cannot be parsed.  By default it is read as
+// zero, or the line is skipped.  A strict tree returns the first ParseError
+// along with what could be parsed; a lenient tree parses like the default and
+// collects every ParseError in its Warnings.
+type Mode interface {
+	ParseMode() (strict bool, warnings *Warnings)
+}
+
+// A procfs tree with a parse mode.  It forwards ReadLink so that [pid]/fd can
+// still be read.
+type modeFS struct {
+	fs.FS
+	strict   bool
+	warnings *Warnings
+}
+
+func (f modeFS) ParseMode() (bool, *Warnings) {
+	return f.strict, f.warnings
+}
+
+func (f modeFS) ReadLink(name string) (string, error) {
+	return fs.ReadLink(f.FS, name)
+}
+
+func (f modeFS) Lstat(name string) (fs.FileInfo, error) {
+	return fs.Lstat(f.FS, name)
+}
+
+// Strict returns fsys in strict mode.
+func Strict(fsys fs.FS) fs.FS {
+	return modeFS{FS: fsys, strict: true}
+}
+
+// Lenient returns fsys in lenient mode, collecting ParseErrors in warnings.
+func Lenient(fsys fs.FS, warnings *Warnings) fs.FS {
+	return modeFS{FS: fsys, warnings: warnings}
+}
+
+// A Checker reports the ParseErrors of one file according to the mode of the
+// procfs tree it is read from.
+// Example:
+//     check := procfs.NewChecker(fsys, "meminfo")
+//     for scanner.Scan() {
+//         check.NextLine()
+//         x := check.Uint64("MemTotal", splits[1], 10)
+//     }
+//     return result, check.Err()
+type Checker struct {
+	file     string
+	pid      int
+	process  bool
+	line     int
+	strict   bool
+	warnings *Warnings
+	err      error
+}
+
+func NewChecker(fsys fs.FS, name string) *Checker {
+	strict, warnings := parseMode(fsys)
+	return &Checker{
+		file:     name,
+		strict:   strict,
+		warnings: warnings,
+	}
+}
+
+// A Checker for the file name in the directory of process pid, e.g. "stat".
+// The path is only built if a ParseError is reported.
+func NewProcessChecker(fsys fs.FS, pid int, name string) *Checker {
+	strict, warnings := parseMode(fsys)
+	return &Checker{
+		file:     name,
+		pid:      pid,
+		process:  true,
+		strict:   strict,
+		warnings: warnings,
+	}
+}
+
+// The mode of fsys.  The constructors of Checker stay small enough to be
+// inlined, so that a Checker need not be allocated.
+func parseMode(fsys fs.FS) (bool, *Warnings) {
+	if mode, ok := fsys.(Mode); ok {
+		return mode.ParseMode()
+	}
+	return false, nil
+}
+
+// Path of the file relative to the procfs root.
+func (c *Checker) filename() string {
+	if c.process {
+		return strconv.Itoa(c.pid) + "/" + c.file
+	}
+	return c.file
+}
+
+// Advance to the next line of the file.
+func (c *Checker) NextLine() {
+	c.line++
+}
+
+// Set the current line of the file, counting from 1.
+func (c *Checker) SetLine(line int) {
+	c.line = line
+}
+
+// Report that text of field could not be parsed because of err.
+func (c *Checker) Report(field string, text string, err error) {
+	parseError := &ParseError{
+		File:  c.filename(),
+		Line:  c.line,
+		Field: field,
+		Text:  text,
+		Err:   cause(err),
+	}
+	if c.strict && c.err == nil {
+		c.err = parseError
+	}
+	if c.warnings != nil {
+		c.warnings.add(parseError)
+	}
+}
+
+// Report line if it has fewer than want fields.
+func (c *Checker) Fields(line string, fields []string, want int) bool {
+	if len(fields) < want {
+		c.Report("", line, ErrShortLine)
+		return false
+	}
+	return true
+}
+
+// strconv wraps its errors in *strconv.NumError, which repeats the text.
+func cause(err error) error {
+	var numError *strconv.NumError
+	if errors.As(err, &numError) {
+		return numError.Err
+	}
+	return err
+}
+
+// Parse an unsigned integer, or report it and return 0.
+func (c *Checker) Uint64(field string, text string, base int) uint64 {
+	result, err := strconv.ParseUint(text, base, 64)
+	if err != nil {
+		c.Report(field, text, err)
+		return 0
+	}
+	return result
+}
+
+// Parse a signed integer, or report it and return 0.
+func (c *Checker) Int64(field string, text string, base int) int64 {
+	result, err := strconv.ParseInt(text, base, 64)
+	if err != nil {
+		c.Report(field, text, err)
+		return 0
+	}
+	return result
+}
+
+// Parse a signed integer that fits an int, or report it and return 0.
+func (c *Checker) Int(field string, text string) int {
+	result, err := strconv.ParseInt(text, 10, strconv.IntSize)
+	if err != nil {
+		c.Report(field, text, err)
+		return 0
+	}
+	return int(result)
+}
+
+// Parse a floating-point number, or report it and return 0.
+func (c *Checker) Float64(field string, text string) float64 {
+	result, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		c.Report(field, text, err)
+		return 0
+	}
+	return result
+}
+
+// The first ParseError of a strict tree, or nil.
+func (c *Checker) Err() error {
+	return c.err
+}
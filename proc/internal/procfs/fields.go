@@ -0,0 +1,106 @@
+package procfs
+
+import (
+	"math"
+	"strconv"
+)
+
+// Helpers to parse a file in place, without the allocations of converting it
+// to strings, for callers that poll at a high rate.
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// NextField splits the first whitespace-separated field off line.  field is
+// empty if line has none left.
+// Example:
+//     field, rest := procfs.NextField([]byte("MemTotal: 16 kB"))
+func NextField(line []byte) (field []byte, rest []byte) {
+	start := 0
+	for start < len(line) && isSpace(line[start]) {
+		start++
+	}
+	end := start
+	for end < len(line) && !isSpace(line[end]) {
+		end++
+	}
+	return line[start:end], line[end:]
+}
+
+// NextLine splits the first line off data, without its newline.
+func NextLine(data []byte) (line []byte, rest []byte) {
+	for index, c := range data {
+		if c == '\n' {
+			return data[:index], data[index+1:]
+		}
+	}
+	return data, nil
+}
+
+// ParseUint parses a decimal unsigned integer like strconv.ParseUint(text,
+// 10, 64).  Its errors are strconv.ErrSyntax and strconv.ErrRange.
+func ParseUint(text []byte) (uint64, error) {
+	if len(text) == 0 {
+		return 0, strconv.ErrSyntax
+	}
+	result := uint64(0)
+	for _, c := range text {
+		if c < '0' || c > '9' {
+			return 0, strconv.ErrSyntax
+		}
+		if result > math.MaxUint64/10 {
+			return math.MaxUint64, strconv.ErrRange
+		}
+		next := result*10 + uint64(c-'0')
+		if next < result*10 {
+			return math.MaxUint64, strconv.ErrRange
+		}
+		result = next
+	}
+	return result, nil
+}
+
+// ParseInt parses a decimal signed integer like strconv.ParseInt(text, 10,
+// 64).
+func ParseInt(text []byte) (int64, error) {
+	negative := false
+	if len(text) > 0 && (text[0] == '-' || text[0] == '+') {
+		negative = text[0] == '-'
+		text = text[1:]
+	}
+	magnitude, err := ParseUint(text)
+	if err == strconv.ErrSyntax {
+		return 0, err
+	}
+	if negative {
+		if err != nil || magnitude > 1<<63 {
+			return math.MinInt64, strconv.ErrRange
+		}
+		return -int64(magnitude), nil
+	}
+	if err != nil || magnitude > math.MaxInt64 {
+		return math.MaxInt64, strconv.ErrRange
+	}
+	return int64(magnitude), nil
+}
+
+// Parse a decimal unsigned integer in place, or report it and return 0.
+func (c *Checker) Uint64Bytes(field string, text []byte) uint64 {
+	result, err := ParseUint(text)
+	if err != nil {
+		c.Report(field, string(text), err)
+		return 0
+	}
+	return result
+}
+
+// Parse a decimal signed integer in place, or report it and return 0.
+func (c *Checker) Int64Bytes(field string, text []byte) int64 {
+	result, err := ParseInt(text)
+	if err != nil {
+		c.Report(field, string(text), err)
+		return 0
+	}
+	return result
+}
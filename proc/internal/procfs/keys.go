@@ -0,0 +1,303 @@
+package procfs
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Naming of the keys of struct fields in JSON.  Keys of maps, such as the
+// names of network interfaces or the lines of meminfo in Extra, are data and
+// keep their names.
+type KeyCase int
+
+const (
+	KernelCase KeyCase = iota // Keys of the json tags, mostly as named by the kernel, e.g. "Active_anon".
+	SnakeCase                 // e.g. "active_anon", "receive_bytes", "default_ttl".
+	CamelCase                 // e.g. "activeAnon", "receiveBytes", "defaultTtl".
+)
+
+var keyCaseNames = []string{"kernel", "snake", "camel"}
+
+func (k KeyCase) String() string {
+	if k < 0 || int(k) >= len(keyCaseNames) {
+		return "KeyCase(" + strconv.Itoa(int(k)) + ")"
+	}
+	return keyCaseNames[k]
+}
+
+// ParseKeyCase parses "kernel", "snake" or "camel", e.g. from a flag.
+func ParseKeyCase(name string) (KeyCase, error) {
+	for index, keyCaseName := range keyCaseNames {
+		if name == keyCaseName {
+			return KeyCase(index), nil
+		}
+	}
+	return KernelCase, fmt.Errorf("proc: unknown key case %q", name)
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Acronyms that the kernel runs into the capitals of the next word, as in
+// "TCPDSACKRecv" and "TCPHPHits".
+var leadingAcronyms = []string{"TCP"}
+
+// Split a key into lower-case words at underscores, at the start of each
+// capitalized word and before each run of digits, e.g. "HugePages_Total"
+// into "huge", "pages", "total", "DefaultTTL" into "default", "ttl" and
+// "InECT0Pkts" into "in", "ect", "0", "pkts".  A lower-case "s" that ends a
+// run of capitals makes it plural, as in "FragOKs": "frag", "oks".
+func words(key string) []string {
+	result := []string{}
+	isSeparator := func(r rune) bool {
+		return r == '_' || r == '-' || r == '(' || r == ')' || r == ' '
+	}
+	for _, part := range strings.FieldsFunc(key, isSeparator) {
+		start := 0
+		for index := 1; index < len(part); index++ {
+			previous, current := part[index-1], part[index]
+			next, afterNext := byte(0), byte(0)
+			if index+1 < len(part) {
+				next = part[index+1]
+			}
+			if index+2 < len(part) {
+				afterNext = part[index+2]
+			}
+			startsWord := isLower(next) && !(next == 's' && !isLower(afterNext))
+			split := false
+			switch {
+			case isDigit(current):
+				split = !isDigit(previous)
+			case isUpper(current):
+				split = isLower(previous) || (!isLower(previous) && startsWord)
+				for _, acronym := range leadingAcronyms {
+					split = split || part[start:index] == acronym
+				}
+			}
+			if split {
+				result = append(result, strings.ToLower(part[start:index]))
+				start = index
+			}
+		}
+		result = append(result, strings.ToLower(part[start:]))
+	}
+	return result
+}
+
+// Key returns key, as found in a json tag, in the case k.
+// Example:
+//     x := procfs.SnakeCase.Key("ReceiveBytes") // "receive_bytes"
+func (k KeyCase) Key(key string) string {
+	switch k {
+	case SnakeCase:
+		return strings.Join(words(key), "_")
+	case CamelCase:
+		result := ""
+		for index, word := range words(key) {
+			if index > 0 {
+				word = strings.ToUpper(word[:1]) + word[1:]
+			}
+			result += word
+		}
+		return result
+	}
+	return key
+}
+
+// The JSON key of a struct field, whether it is left out when empty, and
+// whether it is written at all, as encoding/json decides.
+func FieldKey(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+	if !field.IsExported() || tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	omitEmpty := false
+	for _, option := range strings.Split(options, ",") {
+		omitEmpty = omitEmpty || option == "omitempty"
+	}
+	return name, omitEmpty, true
+}
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// IsMarshaler reports whether values of t write their own JSON.
+func IsMarshaler(t reflect.Type) bool {
+	return t.Implements(marshalerType) || t.Implements(textMarshalerType)
+}
+
+// MarshalJSON writes v like json.Marshal, with the keys of struct fields in
+// the case keys.
+// Example:
+//     myDevs, err := dev.Get()
+//     x, err := procfs.MarshalJSON(myDevs, procfs.SnakeCase)
+func MarshalJSON(v interface{}, keys KeyCase) ([]byte, error) {
+	if keys == KernelCase {
+		return json.Marshal(v)
+	}
+	buffer := &bytes.Buffer{}
+	err := encode(buffer, reflect.ValueOf(v), keys)
+	return buffer.Bytes(), err
+}
+
+// Whether encoding/json leaves out value for "omitempty".
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil()
+	}
+	return value.IsZero()
+}
+
+func encode(buffer *bytes.Buffer, value reflect.Value, keys KeyCase) error {
+	if !value.IsValid() {
+		buffer.WriteString("null")
+		return nil
+	}
+	if IsMarshaler(value.Type()) {
+		return encodeValue(buffer, value)
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			buffer.WriteString("null")
+			return nil
+		}
+		return encode(buffer, value.Elem(), keys)
+
+	case reflect.Struct:
+		buffer.WriteByte('{')
+		written := 0
+		for index := 0; index < value.NumField(); index++ {
+			key, omitEmpty, ok := FieldKey(value.Type().Field(index))
+			if !ok || (omitEmpty && isEmpty(value.Field(index))) {
+				continue
+			}
+			if written > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := encodeValue(buffer, reflect.ValueOf(keys.Key(key))); err != nil {
+				return err
+			}
+			buffer.WriteByte(':')
+			if err := encode(buffer, value.Field(index), keys); err != nil {
+				return err
+			}
+			written++
+		}
+		buffer.WriteByte('}')
+		return nil
+
+	case reflect.Map:
+		if value.IsNil() {
+			buffer.WriteString("null")
+			return nil
+		}
+
+		// Map keys are data; write them as encoding/json does, in sorted
+		// order.
+
+		type entry struct {
+			key   string
+			value reflect.Value
+		}
+		entries := []entry{}
+		iterator := value.MapRange()
+		for iterator.Next() {
+			key, err := MapKey(iterator.Key())
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry{key, iterator.Value()})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+		buffer.WriteByte('{')
+		for index, anEntry := range entries {
+			if index > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := encodeValue(buffer, reflect.ValueOf(anEntry.key)); err != nil {
+				return err
+			}
+			buffer.WriteByte(':')
+			if err := encode(buffer, anEntry.value, keys); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			buffer.WriteString("null")
+			return nil
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return encodeValue(buffer, value) // Base64, as encoding/json writes []byte.
+		}
+		buffer.WriteByte('[')
+		for index := 0; index < value.Len(); index++ {
+			if index > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := encode(buffer, value.Index(index), keys); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+		return nil
+	}
+	return encodeValue(buffer, value)
+}
+
+// Write a value that has no keys to rename.
+func encodeValue(buffer *bytes.Buffer, value reflect.Value) error {
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		return err
+	}
+	buffer.Write(data)
+	return nil
+}
+
+// MapKey returns a map key as encoding/json writes it.
+func MapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if textMarshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		text, err := textMarshaler.MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("proc: unsupported map key type %s", key.Type())
+}
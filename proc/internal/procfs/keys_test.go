@@ -0,0 +1,384 @@
+package procfs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The keys of every header of net/snmp and net/netstat in testdata/, in
+// snake and camel case.
+var headerKeys = []struct {
+	key, snake, camel string
+}{
+	{"ActiveOpens", "active_opens", "activeOpens"},
+	{"AddAddr", "add_addr", "addAddr"},
+	{"AddAddrDrop", "add_addr_drop", "addAddrDrop"},
+	{"AddAddrTx", "add_addr_tx", "addAddrTx"},
+	{"AddAddrTxDrop", "add_addr_tx_drop", "addAddrTxDrop"},
+	{"ArpFilter", "arp_filter", "arpFilter"},
+	{"AttemptFails", "attempt_fails", "attemptFails"},
+	{"BeyondWindow", "beyond_window", "beyondWindow"},
+	{"Blackhole", "blackhole", "blackhole"},
+	{"BusyPollRxPackets", "busy_poll_rx_packets", "busyPollRxPackets"},
+	{"CurrEstab", "curr_estab", "currEstab"},
+	{"DSSCorruptionFallback", "dss_corruption_fallback", "dssCorruptionFallback"},
+	{"DSSCorruptionReset", "dss_corruption_reset", "dssCorruptionReset"},
+	{"DSSNoMatchTCP", "dss_no_match_tcp", "dssNoMatchTcp"},
+	{"DSSNotMatching", "dss_not_matching", "dssNotMatching"},
+	{"DataCsumErr", "data_csum_err", "dataCsumErr"},
+	{"DefaultTTL", "default_ttl", "defaultTtl"},
+	{"DelayedACKLocked", "delayed_ack_locked", "delayedAckLocked"},
+	{"DelayedACKLost", "delayed_ack_lost", "delayedAckLost"},
+	{"DelayedACKs", "delayed_acks", "delayedAcks"},
+	{"DssFallback", "dss_fallback", "dssFallback"},
+	{"DuplicateData", "duplicate_data", "duplicateData"},
+	{"EchoAdd", "echo_add", "echoAdd"},
+	{"EchoAddTx", "echo_add_tx", "echoAddTx"},
+	{"EchoAddTxDrop", "echo_add_tx_drop", "echoAddTxDrop"},
+	{"EmbryonicRsts", "embryonic_rsts", "embryonicRsts"},
+	{"EstabResets", "estab_resets", "estabResets"},
+	{"FallbackFailed", "fallback_failed", "fallbackFailed"},
+	{"ForwDatagrams", "forw_datagrams", "forwDatagrams"},
+	{"Forwarding", "forwarding", "forwarding"},
+	{"FragCreates", "frag_creates", "fragCreates"},
+	{"FragFails", "frag_fails", "fragFails"},
+	{"FragOKs", "frag_oks", "fragOks"},
+	{"IPReversePathFilter", "ip_reverse_path_filter", "ipReversePathFilter"},
+	{"IgnoredMulti", "ignored_multi", "ignoredMulti"},
+	{"InAddrErrors", "in_addr_errors", "inAddrErrors"},
+	{"InAddrMaskReps", "in_addr_mask_reps", "inAddrMaskReps"},
+	{"InAddrMasks", "in_addr_masks", "inAddrMasks"},
+	{"InBcastOctets", "in_bcast_octets", "inBcastOctets"},
+	{"InBcastPkts", "in_bcast_pkts", "inBcastPkts"},
+	{"InCEPkts", "in_ce_pkts", "inCePkts"},
+	{"InCsumErrors", "in_csum_errors", "inCsumErrors"},
+	{"InDatagrams", "in_datagrams", "inDatagrams"},
+	{"InDelivers", "in_delivers", "inDelivers"},
+	{"InDestUnreachs", "in_dest_unreachs", "inDestUnreachs"},
+	{"InDiscards", "in_discards", "inDiscards"},
+	{"InECT0Pkts", "in_ect_0_pkts", "inEct0Pkts"},
+	{"InECT1Pkts", "in_ect_1_pkts", "inEct1Pkts"},
+	{"InEchoReps", "in_echo_reps", "inEchoReps"},
+	{"InEchos", "in_echos", "inEchos"},
+	{"InErrors", "in_errors", "inErrors"},
+	{"InErrs", "in_errs", "inErrs"},
+	{"InHdrErrors", "in_hdr_errors", "inHdrErrors"},
+	{"InMcastOctets", "in_mcast_octets", "inMcastOctets"},
+	{"InMcastPkts", "in_mcast_pkts", "inMcastPkts"},
+	{"InMsgs", "in_msgs", "inMsgs"},
+	{"InNoECTPkts", "in_no_ect_pkts", "inNoEctPkts"},
+	{"InNoRoutes", "in_no_routes", "inNoRoutes"},
+	{"InOctets", "in_octets", "inOctets"},
+	{"InParmProbs", "in_parm_probs", "inParmProbs"},
+	{"InReceives", "in_receives", "inReceives"},
+	{"InRedirects", "in_redirects", "inRedirects"},
+	{"InSegs", "in_segs", "inSegs"},
+	{"InSrcQuenchs", "in_src_quenchs", "inSrcQuenchs"},
+	{"InTimeExcds", "in_time_excds", "inTimeExcds"},
+	{"InTimestampReps", "in_timestamp_reps", "inTimestampReps"},
+	{"InTimestamps", "in_timestamps", "inTimestamps"},
+	{"InTruncatedPkts", "in_truncated_pkts", "inTruncatedPkts"},
+	{"InType3", "in_type_3", "inType3"},
+	{"InType8", "in_type_8", "inType8"},
+	{"InUnknownProtos", "in_unknown_protos", "inUnknownProtos"},
+	{"InfiniteMapRx", "infinite_map_rx", "infiniteMapRx"},
+	{"InfiniteMapTx", "infinite_map_tx", "infiniteMapTx"},
+	{"ListenDrops", "listen_drops", "listenDrops"},
+	{"ListenOverflows", "listen_overflows", "listenOverflows"},
+	{"LockDroppedIcmps", "lock_dropped_icmps", "lockDroppedIcmps"},
+	{"MD5SigFallback", "md_5_sig_fallback", "md5SigFallback"},
+	{"MPCapableACKRX", "mp_capable_ackrx", "mpCapableAckrx"},
+	{"MPCapableDataFallback", "mp_capable_data_fallback", "mpCapableDataFallback"},
+	{"MPCapableEndpAttempt", "mp_capable_endp_attempt", "mpCapableEndpAttempt"},
+	{"MPCapableFallbackACK", "mp_capable_fallback_ack", "mpCapableFallbackAck"},
+	{"MPCapableFallbackSYNACK", "mp_capable_fallback_synack", "mpCapableFallbackSynack"},
+	{"MPCapableSYNACKRX", "mp_capable_synackrx", "mpCapableSynackrx"},
+	{"MPCapableSYNRX", "mp_capable_synrx", "mpCapableSynrx"},
+	{"MPCapableSYNTX", "mp_capable_syntx", "mpCapableSyntx"},
+	{"MPCapableSYNTXDisabled", "mp_capable_syntx_disabled", "mpCapableSyntxDisabled"},
+	{"MPCapableSYNTXDrop", "mp_capable_syntx_drop", "mpCapableSyntxDrop"},
+	{"MPCurrEstab", "mp_curr_estab", "mpCurrEstab"},
+	{"MPFailRx", "mp_fail_rx", "mpFailRx"},
+	{"MPFailTx", "mp_fail_tx", "mpFailTx"},
+	{"MPFallbackTokenInit", "mp_fallback_token_init", "mpFallbackTokenInit"},
+	{"MPFastcloseRx", "mp_fastclose_rx", "mpFastcloseRx"},
+	{"MPFastcloseTx", "mp_fastclose_tx", "mpFastcloseTx"},
+	{"MPJoinAckHMacFailure", "mp_join_ack_h_mac_failure", "mpJoinAckHMacFailure"},
+	{"MPJoinAckRx", "mp_join_ack_rx", "mpJoinAckRx"},
+	{"MPJoinNoTokenFound", "mp_join_no_token_found", "mpJoinNoTokenFound"},
+	{"MPJoinPortAckRx", "mp_join_port_ack_rx", "mpJoinPortAckRx"},
+	{"MPJoinPortSynAckRx", "mp_join_port_syn_ack_rx", "mpJoinPortSynAckRx"},
+	{"MPJoinPortSynRx", "mp_join_port_syn_rx", "mpJoinPortSynRx"},
+	{"MPJoinRejected", "mp_join_rejected", "mpJoinRejected"},
+	{"MPJoinSynAckBackupRx", "mp_join_syn_ack_backup_rx", "mpJoinSynAckBackupRx"},
+	{"MPJoinSynAckHMacFailure", "mp_join_syn_ack_h_mac_failure", "mpJoinSynAckHMacFailure"},
+	{"MPJoinSynAckRx", "mp_join_syn_ack_rx", "mpJoinSynAckRx"},
+	{"MPJoinSynBackupRx", "mp_join_syn_backup_rx", "mpJoinSynBackupRx"},
+	{"MPJoinSynRx", "mp_join_syn_rx", "mpJoinSynRx"},
+	{"MPJoinSynTx", "mp_join_syn_tx", "mpJoinSynTx"},
+	{"MPJoinSynTxBindErr", "mp_join_syn_tx_bind_err", "mpJoinSynTxBindErr"},
+	{"MPJoinSynTxConnectErr", "mp_join_syn_tx_connect_err", "mpJoinSynTxConnectErr"},
+	{"MPJoinSynTxCreatSkErr", "mp_join_syn_tx_creat_sk_err", "mpJoinSynTxCreatSkErr"},
+	{"MPPrioRx", "mp_prio_rx", "mpPrioRx"},
+	{"MPPrioTx", "mp_prio_tx", "mpPrioTx"},
+	{"MPRstRx", "mp_rst_rx", "mpRstRx"},
+	{"MPRstTx", "mp_rst_tx", "mpRstTx"},
+	{"MPTCPRetrans", "mptcp_retrans", "mptcpRetrans"},
+	{"MaxConn", "max_conn", "maxConn"},
+	{"MemErrors", "mem_errors", "memErrors"},
+	{"MismatchPortAckRx", "mismatch_port_ack_rx", "mismatchPortAckRx"},
+	{"MismatchPortSynRx", "mismatch_port_syn_rx", "mismatchPortSynRx"},
+	{"NoDSSInWindow", "no_dss_in_window", "noDssInWindow"},
+	{"NoPorts", "no_ports", "noPorts"},
+	{"OFOMerge", "ofo_merge", "ofoMerge"},
+	{"OFOQueue", "ofo_queue", "ofoQueue"},
+	{"OFOQueueTail", "ofo_queue_tail", "ofoQueueTail"},
+	{"OfoPruned", "ofo_pruned", "ofoPruned"},
+	{"OutAddrMaskReps", "out_addr_mask_reps", "outAddrMaskReps"},
+	{"OutAddrMasks", "out_addr_masks", "outAddrMasks"},
+	{"OutBcastOctets", "out_bcast_octets", "outBcastOctets"},
+	{"OutBcastPkts", "out_bcast_pkts", "outBcastPkts"},
+	{"OutDatagrams", "out_datagrams", "outDatagrams"},
+	{"OutDestUnreachs", "out_dest_unreachs", "outDestUnreachs"},
+	{"OutDiscards", "out_discards", "outDiscards"},
+	{"OutEchoReps", "out_echo_reps", "outEchoReps"},
+	{"OutEchos", "out_echos", "outEchos"},
+	{"OutErrors", "out_errors", "outErrors"},
+	{"OutMcastOctets", "out_mcast_octets", "outMcastOctets"},
+	{"OutMcastPkts", "out_mcast_pkts", "outMcastPkts"},
+	{"OutMsgs", "out_msgs", "outMsgs"},
+	{"OutNoRoutes", "out_no_routes", "outNoRoutes"},
+	{"OutOctets", "out_octets", "outOctets"},
+	{"OutOfWindowIcmps", "out_of_window_icmps", "outOfWindowIcmps"},
+	{"OutParmProbs", "out_parm_probs", "outParmProbs"},
+	{"OutRateLimitGlobal", "out_rate_limit_global", "outRateLimitGlobal"},
+	{"OutRateLimitHost", "out_rate_limit_host", "outRateLimitHost"},
+	{"OutRedirects", "out_redirects", "outRedirects"},
+	{"OutRequests", "out_requests", "outRequests"},
+	{"OutRsts", "out_rsts", "outRsts"},
+	{"OutSegs", "out_segs", "outSegs"},
+	{"OutSrcQuenchs", "out_src_quenchs", "outSrcQuenchs"},
+	{"OutTimeExcds", "out_time_excds", "outTimeExcds"},
+	{"OutTimestampReps", "out_timestamp_reps", "outTimestampReps"},
+	{"OutTimestamps", "out_timestamps", "outTimestamps"},
+	{"OutTransmits", "out_transmits", "outTransmits"},
+	{"OutType0", "out_type_0", "outType0"},
+	{"OutType3", "out_type_3", "outType3"},
+	{"PAWSActive", "paws_active", "pawsActive"},
+	{"PAWSEstab", "paws_estab", "pawsEstab"},
+	{"PAWSOldAck", "paws_old_ack", "pawsOldAck"},
+	{"PAWSTimewait", "paws_timewait", "pawsTimewait"},
+	{"PFMemallocDrop", "pf_memalloc_drop", "pfMemallocDrop"},
+	{"PassiveOpens", "passive_opens", "passiveOpens"},
+	{"PortAdd", "port_add", "portAdd"},
+	{"PruneCalled", "prune_called", "pruneCalled"},
+	{"RcvPruned", "rcv_pruned", "rcvPruned"},
+	{"RcvWndConflict", "rcv_wnd_conflict", "rcvWndConflict"},
+	{"RcvWndConflictUpdate", "rcv_wnd_conflict_update", "rcvWndConflictUpdate"},
+	{"RcvWndShared", "rcv_wnd_shared", "rcvWndShared"},
+	{"RcvbufErrors", "rcvbuf_errors", "rcvbufErrors"},
+	{"ReasmFails", "reasm_fails", "reasmFails"},
+	{"ReasmOKs", "reasm_oks", "reasmOks"},
+	{"ReasmOverlaps", "reasm_overlaps", "reasmOverlaps"},
+	{"ReasmReqds", "reasm_reqds", "reasmReqds"},
+	{"ReasmTimeout", "reasm_timeout", "reasmTimeout"},
+	{"RetransSegs", "retrans_segs", "retransSegs"},
+	{"RmAddr", "rm_addr", "rmAddr"},
+	{"RmAddrDrop", "rm_addr_drop", "rmAddrDrop"},
+	{"RmAddrTx", "rm_addr_tx", "rmAddrTx"},
+	{"RmAddrTxDrop", "rm_addr_tx_drop", "rmAddrTxDrop"},
+	{"RmSubflow", "rm_subflow", "rmSubflow"},
+	{"RtoAlgorithm", "rto_algorithm", "rtoAlgorithm"},
+	{"RtoMax", "rto_max", "rtoMax"},
+	{"RtoMin", "rto_min", "rtoMin"},
+	{"SimultConnectFallback", "simult_connect_fallback", "simultConnectFallback"},
+	{"SndWndShared", "snd_wnd_shared", "sndWndShared"},
+	{"SndbufErrors", "sndbuf_errors", "sndbufErrors"},
+	{"SubflowRecover", "subflow_recover", "subflowRecover"},
+	{"SubflowStale", "subflow_stale", "subflowStale"},
+	{"SyncookiesFailed", "syncookies_failed", "syncookiesFailed"},
+	{"SyncookiesRecv", "syncookies_recv", "syncookiesRecv"},
+	{"SyncookiesSent", "syncookies_sent", "syncookiesSent"},
+	{"TCPACKSkippedChallenge", "tcp_ack_skipped_challenge", "tcpAckSkippedChallenge"},
+	{"TCPACKSkippedFinWait2", "tcp_ack_skipped_fin_wait_2", "tcpAckSkippedFinWait2"},
+	{"TCPACKSkippedPAWS", "tcp_ack_skipped_paws", "tcpAckSkippedPaws"},
+	{"TCPACKSkippedSeq", "tcp_ack_skipped_seq", "tcpAckSkippedSeq"},
+	{"TCPACKSkippedSynRecv", "tcp_ack_skipped_syn_recv", "tcpAckSkippedSynRecv"},
+	{"TCPACKSkippedTimeWait", "tcp_ack_skipped_time_wait", "tcpAckSkippedTimeWait"},
+	{"TCPAOBad", "tcp_ao_bad", "tcpAoBad"},
+	{"TCPAODroppedIcmps", "tcp_ao_dropped_icmps", "tcpAoDroppedIcmps"},
+	{"TCPAOGood", "tcp_ao_good", "tcpAoGood"},
+	{"TCPAOKeyNotFound", "tcp_ao_key_not_found", "tcpAoKeyNotFound"},
+	{"TCPAORequired", "tcp_ao_required", "tcpAoRequired"},
+	{"TCPAbortFailed", "tcp_abort_failed", "tcpAbortFailed"},
+	{"TCPAbortOnClose", "tcp_abort_on_close", "tcpAbortOnClose"},
+	{"TCPAbortOnData", "tcp_abort_on_data", "tcpAbortOnData"},
+	{"TCPAbortOnLinger", "tcp_abort_on_linger", "tcpAbortOnLinger"},
+	{"TCPAbortOnMemory", "tcp_abort_on_memory", "tcpAbortOnMemory"},
+	{"TCPAbortOnTimeout", "tcp_abort_on_timeout", "tcpAbortOnTimeout"},
+	{"TCPAckCompressed", "tcp_ack_compressed", "tcpAckCompressed"},
+	{"TCPAutoCorking", "tcp_auto_corking", "tcpAutoCorking"},
+	{"TCPBacklogCoalesce", "tcp_backlog_coalesce", "tcpBacklogCoalesce"},
+	{"TCPBacklogDrop", "tcp_backlog_drop", "tcpBacklogDrop"},
+	{"TCPChallengeACK", "tcp_challenge_ack", "tcpChallengeAck"},
+	{"TCPDSACKIgnoredDubious", "tcp_dsack_ignored_dubious", "tcpDsackIgnoredDubious"},
+	{"TCPDSACKIgnoredNoUndo", "tcp_dsack_ignored_no_undo", "tcpDsackIgnoredNoUndo"},
+	{"TCPDSACKIgnoredOld", "tcp_dsack_ignored_old", "tcpDsackIgnoredOld"},
+	{"TCPDSACKOfoRecv", "tcp_dsack_ofo_recv", "tcpDsackOfoRecv"},
+	{"TCPDSACKOfoSent", "tcp_dsack_ofo_sent", "tcpDsackOfoSent"},
+	{"TCPDSACKOldSent", "tcp_dsack_old_sent", "tcpDsackOldSent"},
+	{"TCPDSACKRecv", "tcp_dsack_recv", "tcpDsackRecv"},
+	{"TCPDSACKRecvSegs", "tcp_dsack_recv_segs", "tcpDsackRecvSegs"},
+	{"TCPDSACKUndo", "tcp_dsack_undo", "tcpDsackUndo"},
+	{"TCPDeferAcceptDrop", "tcp_defer_accept_drop", "tcpDeferAcceptDrop"},
+	{"TCPDelivered", "tcp_delivered", "tcpDelivered"},
+	{"TCPDeliveredCE", "tcp_delivered_ce", "tcpDeliveredCe"},
+	{"TCPFastOpenActive", "tcp_fast_open_active", "tcpFastOpenActive"},
+	{"TCPFastOpenActiveFail", "tcp_fast_open_active_fail", "tcpFastOpenActiveFail"},
+	{"TCPFastOpenBlackhole", "tcp_fast_open_blackhole", "tcpFastOpenBlackhole"},
+	{"TCPFastOpenCookieReqd", "tcp_fast_open_cookie_reqd", "tcpFastOpenCookieReqd"},
+	{"TCPFastOpenListenOverflow", "tcp_fast_open_listen_overflow", "tcpFastOpenListenOverflow"},
+	{"TCPFastOpenPassive", "tcp_fast_open_passive", "tcpFastOpenPassive"},
+	{"TCPFastOpenPassiveAltKey", "tcp_fast_open_passive_alt_key", "tcpFastOpenPassiveAltKey"},
+	{"TCPFastOpenPassiveFail", "tcp_fast_open_passive_fail", "tcpFastOpenPassiveFail"},
+	{"TCPFastRetrans", "tcp_fast_retrans", "tcpFastRetrans"},
+	{"TCPFromZeroWindowAdv", "tcp_from_zero_window_adv", "tcpFromZeroWindowAdv"},
+	{"TCPFullUndo", "tcp_full_undo", "tcpFullUndo"},
+	{"TCPHPAcks", "tcp_hp_acks", "tcpHpAcks"},
+	{"TCPHPHits", "tcp_hp_hits", "tcpHpHits"},
+	{"TCPHystartDelayCwnd", "tcp_hystart_delay_cwnd", "tcpHystartDelayCwnd"},
+	{"TCPHystartDelayDetect", "tcp_hystart_delay_detect", "tcpHystartDelayDetect"},
+	{"TCPHystartTrainCwnd", "tcp_hystart_train_cwnd", "tcpHystartTrainCwnd"},
+	{"TCPHystartTrainDetect", "tcp_hystart_train_detect", "tcpHystartTrainDetect"},
+	{"TCPKeepAlive", "tcp_keep_alive", "tcpKeepAlive"},
+	{"TCPLossFailures", "tcp_loss_failures", "tcpLossFailures"},
+	{"TCPLossProbeRecovery", "tcp_loss_probe_recovery", "tcpLossProbeRecovery"},
+	{"TCPLossProbes", "tcp_loss_probes", "tcpLossProbes"},
+	{"TCPLossUndo", "tcp_loss_undo", "tcpLossUndo"},
+	{"TCPLostRetransmit", "tcp_lost_retransmit", "tcpLostRetransmit"},
+	{"TCPMD5Failure", "tcp_md_5_failure", "tcpMd5Failure"},
+	{"TCPMD5NotFound", "tcp_md_5_not_found", "tcpMd5NotFound"},
+	{"TCPMD5Unexpected", "tcp_md_5_unexpected", "tcpMd5Unexpected"},
+	{"TCPMTUPFail", "tcp_mtup_fail", "tcpMtupFail"},
+	{"TCPMTUPSuccess", "tcp_mtup_success", "tcpMtupSuccess"},
+	{"TCPMemoryPressures", "tcp_memory_pressures", "tcpMemoryPressures"},
+	{"TCPMemoryPressuresChrono", "tcp_memory_pressures_chrono", "tcpMemoryPressuresChrono"},
+	{"TCPMigrateReqFailure", "tcp_migrate_req_failure", "tcpMigrateReqFailure"},
+	{"TCPMigrateReqSuccess", "tcp_migrate_req_success", "tcpMigrateReqSuccess"},
+	{"TCPMinTTLDrop", "tcp_min_ttl_drop", "tcpMinTtlDrop"},
+	{"TCPOFODrop", "tcp_ofo_drop", "tcpOfoDrop"},
+	{"TCPOFOMerge", "tcp_ofo_merge", "tcpOfoMerge"},
+	{"TCPOFOQueue", "tcp_ofo_queue", "tcpOfoQueue"},
+	{"TCPOrigDataSent", "tcp_orig_data_sent", "tcpOrigDataSent"},
+	{"TCPPLBRehash", "tcp_plb_rehash", "tcpPlbRehash"},
+	{"TCPPartialUndo", "tcp_partial_undo", "tcpPartialUndo"},
+	{"TCPPureAcks", "tcp_pure_acks", "tcpPureAcks"},
+	{"TCPRcvCoalesce", "tcp_rcv_coalesce", "tcpRcvCoalesce"},
+	{"TCPRcvCollapsed", "tcp_rcv_collapsed", "tcpRcvCollapsed"},
+	{"TCPRcvQDrop", "tcp_rcv_q_drop", "tcpRcvQDrop"},
+	{"TCPRenoFailures", "tcp_reno_failures", "tcpRenoFailures"},
+	{"TCPRenoRecovery", "tcp_reno_recovery", "tcpRenoRecovery"},
+	{"TCPRenoRecoveryFail", "tcp_reno_recovery_fail", "tcpRenoRecoveryFail"},
+	{"TCPRenoReorder", "tcp_reno_reorder", "tcpRenoReorder"},
+	{"TCPReqQFullDoCookies", "tcp_req_q_full_do_cookies", "tcpReqQFullDoCookies"},
+	{"TCPReqQFullDrop", "tcp_req_q_full_drop", "tcpReqQFullDrop"},
+	{"TCPRetransFail", "tcp_retrans_fail", "tcpRetransFail"},
+	{"TCPSACKDiscard", "tcp_sack_discard", "tcpSackDiscard"},
+	{"TCPSACKReneging", "tcp_sack_reneging", "tcpSackReneging"},
+	{"TCPSACKReorder", "tcp_sack_reorder", "tcpSackReorder"},
+	{"TCPSYNChallenge", "tcp_syn_challenge", "tcpSynChallenge"},
+	{"TCPSackFailures", "tcp_sack_failures", "tcpSackFailures"},
+	{"TCPSackMerged", "tcp_sack_merged", "tcpSackMerged"},
+	{"TCPSackRecovery", "tcp_sack_recovery", "tcpSackRecovery"},
+	{"TCPSackRecoveryFail", "tcp_sack_recovery_fail", "tcpSackRecoveryFail"},
+	{"TCPSackShiftFallback", "tcp_sack_shift_fallback", "tcpSackShiftFallback"},
+	{"TCPSackShifted", "tcp_sack_shifted", "tcpSackShifted"},
+	{"TCPSlowStartRetrans", "tcp_slow_start_retrans", "tcpSlowStartRetrans"},
+	{"TCPSpuriousRTOs", "tcp_spurious_rtos", "tcpSpuriousRtos"},
+	{"TCPSpuriousRtxHostQueues", "tcp_spurious_rtx_host_queues", "tcpSpuriousRtxHostQueues"},
+	{"TCPSynRetrans", "tcp_syn_retrans", "tcpSynRetrans"},
+	{"TCPTSReorder", "tcp_ts_reorder", "tcpTsReorder"},
+	{"TCPTimeWaitOverflow", "tcp_time_wait_overflow", "tcpTimeWaitOverflow"},
+	{"TCPTimeouts", "tcp_timeouts", "tcpTimeouts"},
+	{"TCPToZeroWindowAdv", "tcp_to_zero_window_adv", "tcpToZeroWindowAdv"},
+	{"TCPWantZeroWindowAdv", "tcp_want_zero_window_adv", "tcpWantZeroWindowAdv"},
+	{"TCPWinProbe", "tcp_win_probe", "tcpWinProbe"},
+	{"TCPWqueueTooBig", "tcp_wqueue_too_big", "tcpWqueueTooBig"},
+	{"TCPZeroWindowDrop", "tcp_zero_window_drop", "tcpZeroWindowDrop"},
+	{"TSEcrRejected", "ts_ecr_rejected", "tsEcrRejected"},
+	{"TW", "tw", "tw"},
+	{"TWKilled", "tw_killed", "twKilled"},
+	{"TWRecycled", "tw_recycled", "twRecycled"},
+	{"TcpDuplicateDataRehash", "tcp_duplicate_data_rehash", "tcpDuplicateDataRehash"},
+	{"TcpTimeoutRehash", "tcp_timeout_rehash", "tcpTimeoutRehash"},
+	{"WinProbe", "win_probe", "winProbe"},
+}
+
+// Keys that are not headers of net/snmp or net/netstat.
+var otherKeys = []struct {
+	key, snake, camel string
+}{
+	{"Active(anon)", "active_anon", "activeAnon"},
+	{"HugePages_Total", "huge_pages_total", "hugePagesTotal"},
+	{"Committed_AS", "committed_as", "committedAs"},
+	{"DirectMap4k", "direct_map_4k", "directMap4k"},
+	{"DirectMap1G", "direct_map_1g", "directMap1g"},
+	{"VmHWM", "vm_hwm", "vmHwm"},
+	{"load15", "load_15", "load15"},
+	{"cpu_percent", "cpu_percent", "cpuPercent"},
+}
+
+func TestKeyCaseKey(t *testing.T) {
+	for _, test := range append(headerKeys, otherKeys...) {
+		if actual := KernelCase.Key(test.key); actual != test.key {
+			t.Errorf("KernelCase.Key(%q) = %q", test.key, actual)
+		}
+		if actual := SnakeCase.Key(test.key); actual != test.snake {
+			t.Errorf("SnakeCase.Key(%q) = %q, want %q", test.key, actual, test.snake)
+		}
+		if actual := CamelCase.Key(test.key); actual != test.camel {
+			t.Errorf("CamelCase.Key(%q) = %q, want %q", test.key, actual, test.camel)
+		}
+	}
+}
+
+// headerKeys holds every header of the trees in testdata/, so that a new
+// tree cannot add a header whose keys are not checked.
+func TestKeyCaseKeyCoversHeaders(t *testing.T) {
+	known := map[string]bool{}
+	for _, test := range headerKeys {
+		known[test.key] = true
+	}
+	paths := []string{}
+	for _, name := range []string{"snmp", "netstat"} {
+		matches, err := filepath.Glob(filepath.Join("..", "..", "..", "testdata", "*linux-*", "net", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no net/snmp or net/netstat in testdata/")
+	}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(file)
+		for line := 0; scanner.Scan(); line++ {
+			if line%2 == 1 {
+				continue // Values.
+			}
+			for _, key := range strings.Fields(scanner.Text())[1:] {
+				if !known[key] {
+					t.Errorf("%s: header %q is not in headerKeys", path, key)
+				}
+			}
+		}
+		file.Close()
+	}
+}
@@ -0,0 +1,253 @@
+package procfs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Mount point of the proc filesystem on a running system.
+const DefaultMountPoint = "/proc"
+
+// Individual files of the default procfs tree may be redirected by OS
+// Environment variables.  Keys are path.Match patterns relative to the
+// procfs root.
+var overrides = []struct {
+	pattern string
+	envVar  string
+}{
+	{"loadavg", "PROC_LOADAVG"},
+	{"meminfo", "PROC_MEMINFO"},
+	{"stat", "PROC_STAT"},
+	{"uptime", "PROC_UPTIME"},
+	{"sys/vm/min_free_kbytes", "PROC_SYS_VM_MIN_FREE_KBYTES"},
+	{"net/dev", "PROC_NET_DEV"},
+	{"net/netstat", "PROC_NET_NETSTAT"},
+	{"net/raw", "PROC_NET_RAW"},
+	{"net/raw6", "PROC_NET_RAW6"},
+	{"net/snmp", "PROC_NET_SNMP"},
+	{"net/tcp", "PROC_NET_TCP"},
+	{"net/tcp6", "PROC_NET_TCP6"},
+	{"net/udp", "PROC_NET_UDP"},
+	{"net/udp6", "PROC_NET_UDP6"},
+	{"*/stat", "PROC_PID_STAT"},
+	{"*/status", "PROC_PID_STATUS"},
+}
+
+// Files returns the files that the packages read from the procfs root, and
+// those they read from each [pid] directory other than fd, as listed in
+// overrides.
+func Files() (files []string, processFiles []string) {
+	for _, entry := range overrides {
+		if name, ok := strings.CutPrefix(entry.pattern, "*/"); ok {
+			processFiles = append(processFiles, name)
+		} else {
+			files = append(files, entry.pattern)
+		}
+	}
+	return files, processFiles
+}
+
+// Root returns the mount point of the default procfs tree: DefaultMountPoint,
+// unless the OS Environment variable PROC_ROOT names another directory, e.g.
+// a snapshot extracted from a recording.
+func Root() string {
+	if root := os.Getenv("PROC_ROOT"); root != "" {
+		return root
+	}
+	return DefaultMountPoint
+}
+
+// Default is the procfs tree mounted at Root, honoring the OS Environment
+// variable overrides.
+var Default fs.FS = defaultFS{}
+
+type defaultFS struct{}
+
+func (f defaultFS) root() fs.FS {
+	return os.DirFS(Root())
+}
+
+func override(name string) string {
+	for _, entry := range overrides {
+		if matched, _ := path.Match(entry.pattern, name); matched {
+			return os.Getenv(entry.envVar)
+		}
+	}
+	return ""
+}
+
+func (f defaultFS) Open(name string) (fs.File, error) {
+	if fileName := override(name); fileName != "" && fs.ValidPath(name) {
+		return os.Open(fileName)
+	}
+	return f.root().Open(name)
+}
+
+// ReadLink implements fs.ReadLinkFS, e.g. for the links of [pid]/fd.
+func (f defaultFS) ReadLink(name string) (string, error) {
+	return fs.ReadLink(f.root(), name)
+}
+
+// Lstat implements fs.ReadLinkFS.
+func (f defaultFS) Lstat(name string) (fs.FileInfo, error) {
+	return fs.Lstat(f.root(), name)
+}
+
+// Filename returns the path on the local filesystem that Default reads for
+// name, a path relative to the procfs root.
+// Example:
+//     x := procfs.Filename("net/dev") // "/proc/net/dev" unless PROC_NET_DEV or PROC_ROOT is set.
+func Filename(name string) string {
+	result := override(name)
+	if result == "" {
+		result = path.Join(Root(), name)
+	}
+	return result
+}
+
+// Pids returns the process IDs in the procfs tree fsys, in ascending order.
+func Pids(fsys fs.FS) ([]int, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	result := []int{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid < 0 {
+			continue
+		}
+		result = append(result, pid)
+	}
+	sort.Ints(result)
+	return result, nil
+}
+
+// IsGone reports whether err means the process being read has exited,
+// which is expected while scanning all processes.
+func IsGone(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ESRCH)
+}
+
+// Size of the first buffer of ReadFile, which holds most procfs files.
+const readSize = 4096
+
+// Size of the buffer at which ReadFile stops growing it and reads the rest
+// of the file in pieces.
+const MaxReadSize = 64 * 1024 * 1024
+
+// ReadFile reads the file name from the procfs tree fsys with a single read.
+// The kernel generates a procfs file on each read, and only what one read
+// returns is consistent: read in pieces, net/tcp may list a socket twice or
+// meminfo mix two moments.  If the buffer fills up, the file is read again
+// from the start into a larger one.  The rest of a file that the kernel hands
+// out in pieces anyway is read as well.
+// Example:
+//     data, err := procfs.ReadFile(fsys, "meminfo")
+//     scanner := procfs.NewScanner(data)
+func ReadFile(fsys fs.FS, name string) ([]byte, error) {
+	size := readSize
+	for {
+		file, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		buffer := make([]byte, size)
+		n, err := file.Read(buffer)
+		if n == size && size < MaxReadSize {
+			file.Close()
+			size *= 2
+			continue
+		}
+		result := buffer[:n]
+		if err == nil {
+			var rest []byte
+			rest, err = io.ReadAll(file)
+			result = append(result, rest...)
+		}
+		file.Close()
+		if err != nil && err != io.EOF {
+			return result, err
+		}
+		return result, nil
+	}
+}
+
+// NewScanner returns a bufio.Scanner of the lines of data, as read by
+// ReadFile.  Unlike bufio.NewScanner, it does not fail on a line longer than
+// 64 KiB, such as "intr" of stat on a machine with many interrupts: any line
+// of data fits its buffer.
+func NewScanner(data []byte) *bufio.Scanner {
+	result := bufio.NewScanner(bytes.NewReader(data))
+	result.Buffer(nil, max(len(data)+1, bufio.MaxScanTokenSize))
+	return result
+}
+
+// ReadTable reads a file such as net/snmp or net/netstat from the procfs tree
+// fsys and calls value with each unparsed value, in the order of the file.
+// Lines alternate between a header naming the fields and the values of those
+// fields, both prefixed by the section, e.g. "Ip:".  Malformed lines are
+// reported to check, which is on the line of the value when value is called.
+// Example:
+//     check := procfs.NewChecker(fsys, "net/snmp")
+//     err := procfs.ReadTable(fsys, "net/snmp", check, func(section string, key string, text string) {
+//         x := check.Uint64(key, text, 10)
+//     })
+func ReadTable(fsys fs.FS, name string, check *Checker, value func(section string, key string, text string)) error {
+
+	// Read the file in a single read.
+
+	data, err := ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	// Oscillate between header and non-header lines in file.
+
+	header := true
+	headerSplits := []string{}
+
+	// Read the file.
+
+	scanner := NewScanner(data)
+	for scanner.Scan() {
+		check.NextLine()
+		inputLine := scanner.Text()
+		splits := strings.Fields(inputLine)
+		if len(splits) == 0 {
+			continue
+		}
+
+		// Pull out the first-level key for the map-map structure.
+
+		keySplits := strings.Split(splits[0], ":")
+		key := keySplits[0]
+
+		// Handle header and data lines in the file.
+
+		if header {
+			headerSplits = splits
+		} else {
+			if splits[0] != headerSplits[0] || len(splits) != len(headerSplits) {
+				check.Report("", inputLine, ErrHeaderMismatch)
+			}
+			for index, split := range splits {
+				if index == 0 || index >= len(headerSplits) {
+					continue
+				}
+				value(key, headerSplits[index], split)
+			}
+		}
+		header = !header // Oscillate between header and non-header lines in file.
+	}
+	return scanner.Err()
+}
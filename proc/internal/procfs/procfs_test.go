@@ -0,0 +1,83 @@
+package procfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Each PROC_* variable redirects its file of Default, and PROC_ROOT the rest.
+func TestDefaultOverrides(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "net"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "net", "dev"), []byte("root\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PROC_ROOT", root)
+	t.Setenv("PROC_NET_DEV", "")
+
+	dir := t.TempDir()
+	for _, entry := range overrides {
+		if entry.envVar == "PROC_NET_DEV" {
+			continue
+		}
+		filename := filepath.Join(dir, entry.envVar)
+		if err := os.WriteFile(filename, []byte(entry.envVar+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv(entry.envVar, filename)
+	}
+
+	for _, entry := range overrides {
+		name := strings.ReplaceAll(entry.pattern, "*", "42")
+		expected, expectedFilename := entry.envVar+"\n", filepath.Join(dir, entry.envVar)
+		if entry.envVar == "PROC_NET_DEV" {
+			expected, expectedFilename = "root\n", filepath.Join(root, "net", "dev")
+		}
+		data, err := fs.ReadFile(Default, name)
+		if string(data) != expected || err != nil {
+			t.Errorf("ReadFile(Default, %q) = %q, %v; want %q", name, data, err, expected)
+		}
+		if actual := Filename(name); actual != expectedFilename {
+			t.Errorf("Filename(%q) = %q; want %q", name, actual, expectedFilename)
+		}
+	}
+
+	// A pattern of a process matches a single directory.
+
+	if _, err := fs.ReadFile(Default, "42/task/42/stat"); err == nil {
+		t.Error("PROC_PID_STAT redirects 42/task/42/stat")
+	}
+}
+
+func TestRoot(t *testing.T) {
+	t.Setenv("PROC_ROOT", "")
+	if actual := Root(); actual != DefaultMountPoint {
+		t.Errorf("Root() = %q; want %q", actual, DefaultMountPoint)
+	}
+	t.Setenv("PROC_ROOT", "/host/proc")
+	t.Setenv("PROC_MEMINFO", "")
+	if actual := Root(); actual != "/host/proc" {
+		t.Errorf("Root() = %q; want /host/proc", actual)
+	}
+	if actual := Filename("meminfo"); actual != "/host/proc/meminfo" {
+		t.Errorf("Filename(meminfo) = %q; want /host/proc/meminfo", actual)
+	}
+}
+
+// Files lists every file that may be redirected.
+func TestFiles(t *testing.T) {
+	files, processFiles := Files()
+	if len(files)+len(processFiles) != len(overrides) {
+		t.Errorf("Files() = %v, %v; want the %d files of overrides", files, processFiles, len(overrides))
+	}
+	for _, name := range processFiles {
+		if strings.Contains(name, "/") || strings.Contains(name, "*") {
+			t.Errorf("Files(): %q is not a name in [pid]", name)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package loadavg
+
+import (
+	"encoding/json"
+	"io/fs"
+	"strings"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// Path of the file relative to the procfs root.
+const filename = "loadavg"
+
+// Load averages over 1, 5 and 15 minutes, the number of currently runnable
+// scheduling entities (processes and threads), the total number of scheduling
+// entities and the PID most recently created.
+// References:
+// - http://man7.org/linux/man-pages/man5/proc.5.html  "/proc/loadavg"
+type Loadavg struct {
+	Load1    float64 `json:"load1"`
+	Load5    float64 `json:"load5"`
+	Load15   float64 `json:"load15"`
+	Runnable int     `json:"runnable"`
+	Total    int     `json:"total"`
+	Last_pid int     `json:"last_pid"`
+}
+
+// Allow filename to be specified by OS Environment variable: PROC_LOADAVG
+func GetFilename() string {
+	return procfs.Filename(filename)
+}
+
+// Get values of /proc/loadavg.
+// Example:
+//     myLoadavg, err := loadavg.Get()
+//     x := myLoadavg.Load1
+func Get() (Loadavg, error) {
+	return GetFrom(procfs.Default)
+}
+
+// Get values of loadavg from the procfs tree fsys.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     myLoadavg, err := loadavg.GetFrom(myFS)
+func GetFrom(fsys fs.FS) (Loadavg, error) {
+
+	result := Loadavg{}
+
+	// Read the file in a single read.
+
+	data, err := procfs.ReadFile(fsys, filename)
+	if err != nil {
+		return result, err
+	}
+
+	// Parse the file, e.g. "0.25 0.23 0.16 2/74 9886".
+
+	check := procfs.NewChecker(fsys, filename)
+	scanner := procfs.NewScanner(data)
+	if !scanner.Scan() {
+		return result, scanner.Err()
+	}
+	check.NextLine()
+	inputLine := scanner.Text()
+	splits := strings.Fields(inputLine)
+	if !check.Fields(inputLine, splits, 5) {
+		return result, check.Err()
+	}
+
+	result.Load1 = check.Float64("load1", splits[0])
+	result.Load5 = check.Float64("load5", splits[1])
+	result.Load15 = check.Float64("load15", splits[2])
+	if runnable, total, found := strings.Cut(splits[3], "/"); found {
+		result.Runnable = check.Int("runnable", runnable)
+		result.Total = check.Int("total", total)
+	} else {
+		check.Report("runnable/total", splits[3], procfs.ErrShortLine)
+	}
+	result.Last_pid = check.Int("last_pid", splits[4])
+	return result, check.Err()
+}
+
+func GetAsJson() ([]byte, error) {
+	return GetAsJsonFrom(procfs.Default)
+}
+
+func GetAsJsonFrom(fsys fs.FS) ([]byte, error) {
+	content, err := GetFrom(fsys)
+	if err != nil {
+		return []byte{}, err
+	}
+	return json.Marshal(content)
+}
+
+// Get values of /proc/loadavg as a map of interface{}.
+// Example:
+//     myLoadavg := loadavg.GetAsMap()
+//     x := myLoadavg["load1"]
+func GetAsMap() (map[string]interface{}, error) {
+	return GetAsMapFrom(procfs.Default)
+}
+
+// Get values of loadavg from the procfs tree fsys as a map of interface{}.
+func GetAsMapFrom(fsys fs.FS) (map[string]interface{}, error) {
+
+	result := make(map[string]interface{})
+	loadavg, err := GetFrom(fsys)
+	if err != nil {
+		return result, err
+	}
+
+	result["load1"] = loadavg.Load1
+	result["load5"] = loadavg.Load5
+	result["load15"] = loadavg.Load15
+	result["runnable"] = loadavg.Runnable
+	result["total"] = loadavg.Total
+	result["last_pid"] = loadavg.Last_pid
+
+	return result, nil
+}
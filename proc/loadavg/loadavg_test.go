@@ -0,0 +1,80 @@
+package loadavg
+
+import (
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"io/fs"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// The trees under testdata/, read from an fs.FS and, through PROC_ROOT, from
+// the default procfs tree.
+func TestGolden(t *testing.T) {
+	for _, tree := range golden.Trees(t) {
+		t.Run(tree.Name, func(t *testing.T) {
+			content, err := GetFrom(tree.FS)
+			golden.Check(t, tree, "loadavg", content, err)
+		})
+		t.Run(tree.Name+"/PROC_ROOT", func(t *testing.T) {
+			tree.SetRoot(t)
+			content, err := Get()
+			golden.Check(t, tree, "loadavg", content, err)
+		})
+	}
+}
+
+// The loads and the runnable/total pair, split at the slash.
+func TestGetFrom(t *testing.T) {
+	loadavg, err := GetFrom(golden.Find(t, "linux-6.18-container-x86_64").FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Loadavg{Load1: 0.05, Load5: 0.13, Load15: 0.15, Runnable: 1, Total: 75, Last_pid: 20847}
+	if loadavg != expected {
+		t.Errorf("got %+v, want %+v", loadavg, expected)
+	}
+}
+
+// A malformed line gives a ParseError in strict mode and a warning in lenient
+// mode; the other fields are still read.
+func TestGetFromMalformed(t *testing.T) {
+	tests := []golden.Malformed{
+		{
+			Name:     "load",
+			Data:     "0.05 x 0.15 1/75 20847\n",
+			Expected: Loadavg{Load1: 0.05, Load15: 0.15, Runnable: 1, Total: 75, Last_pid: 20847},
+			Wants:    []procfs.ParseError{{File: "loadavg", Line: 1, Field: "load5", Text: "x", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "no slash",
+			Data:     "0.05 0.13 0.15 75 20847\n",
+			Expected: Loadavg{Load1: 0.05, Load5: 0.13, Load15: 0.15, Last_pid: 20847},
+			Wants:    []procfs.ParseError{{File: "loadavg", Line: 1, Field: "runnable/total", Text: "75", Err: procfs.ErrShortLine}},
+		},
+		{
+			Name:     "short line",
+			Data:     "0.05 0.13 0.15\n",
+			Expected: Loadavg{},
+			Wants:    []procfs.ParseError{{File: "loadavg", Line: 1, Text: "0.05 0.13 0.15", Err: procfs.ErrShortLine}},
+		},
+	}
+	for _, test := range tests {
+		golden.CheckMalformed(t, "loadavg", test, func(fsys fs.FS) (interface{}, error) { return GetFrom(fsys) })
+	}
+}
+
+// Parse any loadavg without panicking, into a result or a ParseError.
+func FuzzGetFrom(f *testing.F) {
+	golden.Seed(f, "loadavg")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, fsys := range golden.Modes(fstest.MapFS{"loadavg": {Data: data}}) {
+			_, err := GetFrom(fsys)
+			golden.CheckError(t, err)
+			_, err = GetAsMapFrom(fsys)
+			golden.CheckError(t, err)
+		}
+	})
+}
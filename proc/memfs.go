@@ -0,0 +1,179 @@
+package proc
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// A procfs tree held in memory, such as a snapshot of a recording.  It holds
+// files and symlinks by their path; directories are implied by the paths
+// below them.  As with os.DirFS, Open follows a symlink, and one whose target
+// is not in the tree does not exist for Open.  That is the case of the links
+// of [pid]/fd, which name sockets and pipes, so they are read with ReadLink,
+// and fstest.TestFS fails on a tree with such links.
+type memFS struct {
+	files    map[string]*memFile
+	children map[string][]*memFile
+}
+
+// A file, symlink or directory of a memFS.  It is its own fs.FileInfo and
+// fs.DirEntry.
+type memFile struct {
+	name    string
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func newMemFS() *memFS {
+	return &memFS{
+		files:    map[string]*memFile{".": {name: ".", mode: fs.ModeDir | 0555}},
+		children: make(map[string][]*memFile),
+	}
+}
+
+// Add the file name, and the directories above it that are not yet in m.
+func (m *memFS) add(name string, data []byte, mode fs.FileMode, modTime time.Time) {
+	if _, ok := m.files[name]; ok || !fs.ValidPath(name) || name == "." {
+		return
+	}
+	file := &memFile{name: path.Base(name), data: data, mode: mode, modTime: modTime}
+	m.files[name] = file
+	dir := path.Dir(name)
+	m.add(dir, nil, fs.ModeDir|0555, modTime)
+	m.children[dir] = append(m.children[dir], file)
+}
+
+// The file name, or an *fs.PathError for op.
+func (m *memFS) lookup(op string, name string) (*memFile, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	file, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return file, nil
+}
+
+// Most symlinks Open follows from one name, as Linux does.
+const maxSymlinks = 40
+
+func (m *memFS) Open(name string) (fs.File, error) {
+	file, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Follow symlinks.  A target that is absolute, such as "/dev/null", is
+	// outside of the tree; one such as "socket:[1234]" names no file of it.
+
+	target := name
+	for count := 0; file.mode&fs.ModeSymlink != 0; count++ {
+		link := string(file.data)
+		target = path.Join(path.Dir(target), link)
+		ok := false
+		if count < maxSymlinks && !path.IsAbs(link) && fs.ValidPath(target) {
+			file, ok = m.files[target]
+		}
+		if !ok {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	if file.IsDir() {
+		entries, _ := m.ReadDir(target)
+		return &memDir{file: file, entries: entries}, nil
+	}
+	return &memReader{file: file, Reader: bytes.NewReader(file.data)}, nil
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	file, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !file.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	result := []fs.DirEntry{}
+	for _, child := range m.children[name] {
+		result = append(result, child)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result, nil
+}
+
+// ReadLink implements fs.ReadLinkFS.
+func (m *memFS) ReadLink(name string) (string, error) {
+	file, err := m.lookup("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	if file.mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return string(file.data), nil
+}
+
+// Lstat implements fs.ReadLinkFS.
+func (m *memFS) Lstat(name string) (fs.FileInfo, error) {
+	file, err := m.lookup("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (f *memFile) Name() string               { return f.name }
+func (f *memFile) Size() int64                { return int64(len(f.data)) }
+func (f *memFile) Mode() fs.FileMode          { return f.mode }
+func (f *memFile) ModTime() time.Time         { return f.modTime }
+func (f *memFile) IsDir() bool                { return f.mode.IsDir() }
+func (f *memFile) Sys() interface{}           { return nil }
+func (f *memFile) Type() fs.FileMode          { return f.mode.Type() }
+func (f *memFile) Info() (fs.FileInfo, error) { return f, nil }
+
+// An open file of a memFS.  It implements io.ReaderAt, so that a Parser can
+// read it again.
+type memReader struct {
+	file *memFile
+	*bytes.Reader
+}
+
+func (r *memReader) Stat() (fs.FileInfo, error) { return r.file, nil }
+func (r *memReader) Close() error               { return nil }
+
+// An open directory of a memFS.
+type memDir struct {
+	file    *memFile
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.file, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read(buffer []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.file.name, Err: fs.ErrInvalid}
+}
+
+func (d *memDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	d.offset += count
+	return rest[:count], nil
+}
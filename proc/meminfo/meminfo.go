@@ -1,13 +1,18 @@
 package meminfo
 
 import (
-	"bufio"
+	"bytes"
 	"encoding/json"
-	"os"
-	"strconv"
-	"strings"
+	"io/fs"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
 )
 
+// Path of the file relative to the procfs root.
+const filename = "meminfo"
+
+// Values are in kB (KiB) except for the HugePages_* counts; see Unit and
+// Bytes.
 // References:
 // - http://man7.org/linux/man-pages/man5/proc.5.html  "/proc/meminfo"
 type Meminfo struct {
@@ -32,16 +37,21 @@ type Meminfo struct {
 	MmapCopy          uint64 `json:"MmapCopy"`
 	SwapTotal         uint64 `json:"SwapTotal"`
 	SwapFree          uint64 `json:"SwapFree"`
+	Zswap             uint64 `json:"Zswap"`
+	Zswapped          uint64 `json:"Zswapped"`
 	Dirty             uint64 `json:"Dirty"`
 	Writeback         uint64 `json:"Writeback"`
 	AnonPages         uint64 `json:"AnonPages"`
 	Mapped            uint64 `json:"Mapped"`
 	Shmem             uint64 `json:"Shmem"`
+	KReclaimable      uint64 `json:"KReclaimable"`
 	Slab              uint64 `json:"Slab"`
 	SReclaimable      uint64 `json:"SReclaimable"`
 	SUnreclaim        uint64 `json:"SUnreclaim"`
 	KernelStack       uint64 `json:"KernelStack"`
+	ShadowCallStack   uint64 `json:"ShadowCallStack"`
 	PageTables        uint64 `json:"PageTables"`
+	SecPageTables     uint64 `json:"SecPageTables"`
 	Quicklists        uint64 `json:"Quicklists"`
 	NFS_Unstable      uint64 `json:"NFS_Unstable"`
 	Bounce            uint64 `json:"Bounce"`
@@ -51,237 +61,369 @@ type Meminfo struct {
 	VmallocTotal      uint64 `json:"VmallocTotal"`
 	VmallocUsed       uint64 `json:"VmallocUsed"`
 	VmallocChunk      uint64 `json:"VmallocChunk"`
+	Percpu            uint64 `json:"Percpu"`
 	HardwareCorrupted uint64 `json:"HardwareCorrupted"`
 	AnonHugePages     uint64 `json:"AnonHugePages"`
 	ShmemHugePages    uint64 `json:"ShmemHugePages"`
 	ShmemPmdMapped    uint64 `json:"ShmemPmdMapped"`
+	FileHugePages     uint64 `json:"FileHugePages"`
+	FilePmdMapped     uint64 `json:"FilePmdMapped"`
 	CmaTotal          uint64 `json:"CmaTotal"`
 	CmaFree           uint64 `json:"CmaFree"`
-	HugePages_Total   uint64 `json:"HugePages_Total"`
-	HugePages_Free    uint64 `json:"HugePages_Free"`
-	HugePages_Rsvd    uint64 `json:"HugePages_Rsvd"`
-	HugePages_Surp    uint64 `json:"HugePages_Surp"`
+	Unaccepted        uint64 `json:"Unaccepted"`
+	Balloon           uint64 `json:"Balloon"`
 	Hugepagesize      uint64 `json:"Hugepagesize"`
+	Hugetlb           uint64 `json:"Hugetlb"`
 	DirectMap4k       uint64 `json:"DirectMap4k"`
 	DirectMap4M       uint64 `json:"DirectMap4M"`
 	DirectMap2M       uint64 `json:"DirectMap2M"`
 	DirectMap1G       uint64 `json:"DirectMap1G"`
+
+	// Numbers of huge pages, not kB.
+	HugePages_Total uint64 `json:"HugePages_Total"`
+	HugePages_Free  uint64 `json:"HugePages_Free"`
+	HugePages_Rsvd  uint64 `json:"HugePages_Rsvd"`
+	HugePages_Surp  uint64 `json:"HugePages_Surp"`
+
+	// Lines not known to this package, e.g. from a newer kernel.
+	Extra map[string]uint64 `json:"Extra,omitempty"`
+
+	// Unit of each line as written by the kernel, keyed like GetAsMap, e.g.
+	// "kB", or "" for a count.  It is not part of the JSON; use Unit, which
+	// also knows the units of a Meminfo read back from JSON.
+	Units map[string]string `json:"-"`
 }
 
-// Allow filename to be specified by OS Environment variable: PROC_NET_SNMP
+// Allow filename to be specified by OS Environment variable: PROC_MEMINFO
 func GetFilename() string {
-	result := os.Getenv("PROC_MEMINFO")
-	if result == "" {
-		result = "/proc/meminfo"
-	}
-	return result
+	return procfs.Filename(filename)
 }
 
-func asUint64(value string) uint64 {
-	result, err := strconv.ParseUint(value, 10, 64)
-	if err != nil {
-		return uint64(0)
+// Field of the struct holding the line key, or nil if there is none.
+func (m *Meminfo) field(key string) *uint64 {
+	switch key {
+	case "MemTotal":
+		return &m.MemTotal
+	case "MemFree":
+		return &m.MemFree
+	case "MemAvailable":
+		return &m.MemAvailable
+	case "Buffers":
+		return &m.Buffers
+	case "Cached":
+		return &m.Cached
+	case "SwapCached":
+		return &m.SwapCached
+	case "Active":
+		return &m.Active
+	case "Inactive":
+		return &m.Inactive
+	case "Active(anon)":
+		return &m.Active_anon
+	case "Inactive(anon)":
+		return &m.Inactive_anon
+	case "Active(file)":
+		return &m.Active_file
+	case "Inactive(file)":
+		return &m.Inactive_file
+	case "Unevictable":
+		return &m.Unevictable
+	case "Mlocked":
+		return &m.Mlocked
+	case "HighTotal":
+		return &m.HighTotal
+	case "HighFree":
+		return &m.HighFree
+	case "LowTotal":
+		return &m.LowTotal
+	case "LowFree":
+		return &m.LowFree
+	case "MmapCopy":
+		return &m.MmapCopy
+	case "SwapTotal":
+		return &m.SwapTotal
+	case "SwapFree":
+		return &m.SwapFree
+	case "Zswap":
+		return &m.Zswap
+	case "Zswapped":
+		return &m.Zswapped
+	case "Dirty":
+		return &m.Dirty
+	case "Writeback":
+		return &m.Writeback
+	case "AnonPages":
+		return &m.AnonPages
+	case "Mapped":
+		return &m.Mapped
+	case "Shmem":
+		return &m.Shmem
+	case "KReclaimable":
+		return &m.KReclaimable
+	case "Slab":
+		return &m.Slab
+	case "SReclaimable":
+		return &m.SReclaimable
+	case "SUnreclaim":
+		return &m.SUnreclaim
+	case "KernelStack":
+		return &m.KernelStack
+	case "ShadowCallStack":
+		return &m.ShadowCallStack
+	case "PageTables":
+		return &m.PageTables
+	case "SecPageTables":
+		return &m.SecPageTables
+	case "Quicklists":
+		return &m.Quicklists
+	case "NFS_Unstable":
+		return &m.NFS_Unstable
+	case "Bounce":
+		return &m.Bounce
+	case "WritebackTmp":
+		return &m.WritebackTmp
+	case "CommitLimit":
+		return &m.CommitLimit
+	case "Committed_AS":
+		return &m.Committed_AS
+	case "VmallocTotal":
+		return &m.VmallocTotal
+	case "VmallocUsed":
+		return &m.VmallocUsed
+	case "VmallocChunk":
+		return &m.VmallocChunk
+	case "Percpu":
+		return &m.Percpu
+	case "HardwareCorrupted":
+		return &m.HardwareCorrupted
+	case "AnonHugePages":
+		return &m.AnonHugePages
+	case "ShmemHugePages":
+		return &m.ShmemHugePages
+	case "ShmemPmdMapped":
+		return &m.ShmemPmdMapped
+	case "FileHugePages":
+		return &m.FileHugePages
+	case "FilePmdMapped":
+		return &m.FilePmdMapped
+	case "CmaTotal":
+		return &m.CmaTotal
+	case "CmaFree":
+		return &m.CmaFree
+	case "Unaccepted":
+		return &m.Unaccepted
+	case "Balloon":
+		return &m.Balloon
+	case "HugePages_Total":
+		return &m.HugePages_Total
+	case "HugePages_Free":
+		return &m.HugePages_Free
+	case "HugePages_Rsvd":
+		return &m.HugePages_Rsvd
+	case "HugePages_Surp":
+		return &m.HugePages_Surp
+	case "Hugepagesize":
+		return &m.Hugepagesize
+	case "Hugetlb":
+		return &m.Hugetlb
+	case "DirectMap4k":
+		return &m.DirectMap4k
+	case "DirectMap4M":
+		return &m.DirectMap4M
+	case "DirectMap2M":
+		return &m.DirectMap2M
+	case "DirectMap1G":
+		return &m.DirectMap1G
 	}
-	return result
+	return nil
 }
 
-// Get values of /proc/meminfo as a map of uint64.
+// Get values of /proc/meminfo.
 // Example:
 //     myMeminfo := meminfo.Get()
-//     x := myMeminfo["MemTotal"]
+//     x := myMeminfo.MemTotal
 func Get() (Meminfo, error) {
+	return GetFrom(procfs.Default)
+}
+
+// Get values of meminfo from the procfs tree fsys.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     myMeminfo, err := meminfo.GetFrom(myFS)
+func GetFrom(fsys fs.FS) (Meminfo, error) {
 
-	result := Meminfo{}
+	result := Meminfo{
+		Extra: make(map[string]uint64),
+		Units: make(map[string]string),
+	}
 
-	// Open the file.
+	// Read the file in a single read.
 
-	fileName := GetFilename()
-	file, err := os.Open(fileName)
+	data, err := procfs.ReadFile(fsys, filename)
 	if err != nil {
 		return result, err
 	}
 
-	// Read the file.
+	// Parse the file.
+
+	err = ParseInto(fsys, data, &result)
+	return result, err
+}
+
+// Parse data read from meminfo of the procfs tree fsys into result, which may
+// hold the values of an earlier read.  fsys is only consulted for its parse
+// mode.  Once result holds the lines of data, it does not allocate unless
+// lines are added to Extra or a value cannot be parsed.
+// Example:
+//     myMeminfo := meminfo.Meminfo{}
+//     data, err := os.ReadFile("/proc/meminfo")
+//     err = meminfo.ParseInto(os.DirFS("/proc"), data, &myMeminfo)
+func ParseInto(fsys fs.FS, data []byte, result *Meminfo) error {
+	check := procfs.NewChecker(fsys, filename)
+	lines := parseLines(check, data, result)
+
+	// Lines read before but missing now are left in Units and Extra; parse
+	// again into empty maps, reporting nothing twice.
+
+	if len(result.Units) > lines {
+		clear(result.Units)
+		clear(result.Extra)
+		parseLines(procfs.NewChecker(nil, filename), data, result)
+	}
+	return check.Err()
+}
+
+// Parse the lines of meminfo into result and return the number of values.
+func parseLines(check *procfs.Checker, data []byte, result *Meminfo) int {
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		inputLine := scanner.Text()
-		splits := strings.Fields(inputLine)
+	extra, units := result.Extra, result.Units
+	if extra == nil {
+		extra = make(map[string]uint64)
+	}
+	if units == nil {
+		units = make(map[string]string)
+	}
+	*result = Meminfo{
+		Extra: extra,
+		Units: units,
+	}
+
+	lines := 0
+	for len(data) > 0 {
+		var inputLine []byte
+		inputLine, data = procfs.NextLine(data)
+		check.NextLine()
 
-		// Pull out the key.
+		// Pull out the key, e.g. "Active(anon):".
 
-		keySplits := strings.Split(splits[0], ":")
-		key := keySplits[0]
+		key, rest := procfs.NextField(inputLine)
+		if len(key) == 0 {
+			continue
+		}
+		if index := bytes.IndexByte(key, ':'); index >= 0 {
+			key = key[:index]
+		}
 
-		// Pull out the value.
+		// Pull out the value and its unit.
 
-		value, err := strconv.ParseUint(splits[1], 10, 64)
+		split, rest := procfs.NextField(rest)
+		if len(split) == 0 {
+			check.Report("", string(inputLine), procfs.ErrShortLine)
+			continue
+		}
+		value, err := procfs.ParseUint(split)
 		if err != nil {
+			check.Report(string(key), string(split), err)
 			continue
 		}
+		unit, _ := procfs.NextField(rest)
+		lines++
 
-		switch key {
-		case "MemTotal":
-			result.MemTotal = value
-		case "MemFree":
-			result.MemFree = value
-		case "MemAvailable":
-			result.MemAvailable = value
-		case "Buffers":
-			result.Buffers = value
-		case "Cached":
-			result.Cached = value
-		case "SwapCached":
-			result.SwapCached = value
-		case "Active":
-			result.Active = value
-		case "Inactive":
-			result.Inactive = value
-		case "Active(anon)":
-			result.Active_anon = value
-		case "Inactive(anon)":
-			result.Inactive_anon = value
-		case "Active(file)":
-			result.Active_file = value
-		case "Inactive(file)":
-			result.Inactive_file = value
-		case "Unevictable":
-			result.Unevictable = value
-		case "Mlocked":
-			result.Mlocked = value
-		case "HighTotal":
-			result.HighTotal = value
-		case "HighFree":
-			result.HighFree = value
-		case "LowTotal":
-			result.LowTotal = value
-		case "LowFree":
-			result.LowFree = value
-		case "MmapCopy":
-			result.MmapCopy = value
-		case "SwapTotal":
-			result.SwapTotal = value
-		case "SwapFree":
-			result.SwapFree = value
-		case "Dirty":
-			result.Dirty = value
-		case "Writeback":
-			result.Writeback = value
-		case "AnonPages":
-			result.AnonPages = value
-		case "Mapped":
-			result.Mapped = value
-		case "Shmem":
-			result.Shmem = value
-		case "Slab":
-			result.Slab = value
-		case "SReclaimable":
-			result.SReclaimable = value
-		case "SUnreclaim":
-			result.SUnreclaim = value
-		case "KernelStack":
-			result.KernelStack = value
-		case "PageTables":
-			result.PageTables = value
-		case "Quicklists":
-			result.Quicklists = value
-		case "NFS_Unstable":
-			result.NFS_Unstable = value
-		case "Bounce":
-			result.Bounce = value
-		case "WritebackTmp":
-			result.WritebackTmp = value
-		case "CommitLimit":
-			result.CommitLimit = value
-		case "Committed_AS":
-			result.Committed_AS = value
-		case "VmallocTotal":
-			result.VmallocTotal = value
-		case "VmallocUsed":
-			result.VmallocUsed = value
-		case "VmallocChunk":
-			result.VmallocChunk = value
-		case "HardwareCorrupted":
-			result.HardwareCorrupted = value
-		case "AnonHugePages":
-			result.AnonHugePages = value
-		case "ShmemHugePages":
-			result.ShmemHugePages = value
-		case "ShmemPmdMapped":
-			result.ShmemPmdMapped = value
-		case "CmaTotal":
-			result.CmaTotal = value
-		case "CmaFree":
-			result.CmaFree = value
-		case "HugePages_Total":
-			result.HugePages_Total = value
-		case "HugePages_Free":
-			result.HugePages_Free = value
-		case "HugePages_Rsvd":
-			result.HugePages_Rsvd = value
-		case "HugePages_Surp":
-			result.HugePages_Surp = value
-		case "Hugepagesize":
-			result.Hugepagesize = value
-		case "DirectMap4k":
-			result.DirectMap4k = value
-		case "DirectMap4M":
-			result.DirectMap4M = value
-		case "DirectMap2M":
-			result.DirectMap2M = value
-		case "DirectMap1G":
-			result.DirectMap1G = value
+		if old, ok := result.Units[string(key)]; !ok || old != string(unit) {
+			result.Units[string(key)] = string(unit)
+		}
+		if field := result.field(string(key)); field != nil {
+			*field = value
+		} else {
+			setExtra(result.Extra, key, value)
 		}
+	}
+	return lines
+}
 
+// Set the line key of extra to value.  Assigning to extra[string(key)]
+// allocates the key even if it is in extra already, so a line read before is
+// updated under the key extra holds.
+func setExtra(extra map[string]uint64, key []byte, value uint64) {
+	old, ok := extra[string(key)]
+	if !ok {
+		extra[string(key)] = value
+		return
+	}
+	if old == value {
+		return
+	}
+	for existing := range extra {
+		if existing == string(key) {
+			extra[existing] = value
+			return
+		}
 	}
-	return result, nil
 }
 
 func GetAsJson() ([]byte, error) {
-	content, err := Get()
+	return GetAsJsonFrom(procfs.Default)
+}
+
+func GetAsJsonFrom(fsys fs.FS) ([]byte, error) {
+	content, err := GetFrom(fsys)
 	if err != nil {
 		return []byte{}, err
 	}
-	result, _ := json.Marshal(content)
-	return result, nil
+	return json.Marshal(content)
 }
 
-// Get values of /proc/meminfo as a map of uint64.
+// Get values of /proc/meminfo as JSON with the keys of struct fields in the case
+// keys.  Keys of maps are left as they are.
 // Example:
-//     myMeminfo := meminfo.Get()
-//     x := myMeminfo["MemTotal"]
-func GetAsMap() (map[string]uint64, error) {
-
-	result := make(map[string]uint64)
-
-	// Open the file.
+//     myJson, err := meminfo.GetAsJsonKeys(proc.CamelCase) // {"memTotal":...}
+func GetAsJsonKeys(keys procfs.KeyCase) ([]byte, error) {
+	return GetAsJsonKeysFrom(procfs.Default, keys)
+}
 
-	fileName := GetFilename()
-	file, err := os.Open(fileName)
+func GetAsJsonKeysFrom(fsys fs.FS, keys procfs.KeyCase) ([]byte, error) {
+	content, err := GetFrom(fsys)
 	if err != nil {
-		return result, err
+		return []byte{}, err
 	}
+	return procfs.MarshalJSON(content, keys)
+}
 
-	// Read the file.
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		inputLine := scanner.Text()
-		splits := strings.Fields(inputLine)
-
-		// Pull out the key.
-
-		keySplits := strings.Split(splits[0], ":")
-		key := keySplits[0]
+// Get values of /proc/meminfo as a map of uint64.
+// Example:
+//     myMeminfo := meminfo.GetAsMap()
+//     x := myMeminfo["MemTotal"]
+func GetAsMap() (map[string]uint64, error) {
+	return GetAsMapFrom(procfs.Default)
+}
 
-		// Pull out the value.
+// Get values of meminfo from the procfs tree fsys as a map of uint64.  The
+// map is built from the same read of the file as Get.
+func GetAsMapFrom(fsys fs.FS) (map[string]uint64, error) {
+	meminfo, err := GetFrom(fsys)
+	return meminfo.AsMap(), err
+}
 
-		value, err := strconv.ParseUint(splits[1], 10, 64)
-		if err != nil {
-			continue
+// Raw values of m keyed like /proc/meminfo, including lines in Extra.
+// Example:
+//     myMeminfo, err := meminfo.Get()
+//     x := myMeminfo.AsMap()["Active(anon)"]
+func (m Meminfo) AsMap() map[string]uint64 {
+	result := make(map[string]uint64)
+	for key := range m.Units {
+		if value, ok := m.Value(key); ok {
+			result[key] = value
 		}
-
-		result[key] = value
 	}
-	return result, nil
+	return result
 }
@@ -0,0 +1,118 @@
+package meminfo
+
+import (
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"io/fs"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// The trees under testdata/, read from an fs.FS and, through PROC_ROOT, from
+// the default procfs tree.
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name       string
+		getFrom    func(tree golden.Tree) (interface{}, error)
+		getDefault func() (interface{}, error)
+	}{
+		{
+			"meminfo",
+			func(tree golden.Tree) (interface{}, error) { return GetFrom(tree.FS) },
+			func() (interface{}, error) { return Get() },
+		},
+		{
+			"meminfo_summary",
+			func(tree golden.Tree) (interface{}, error) { return GetSummaryFrom(tree.FS) },
+			func() (interface{}, error) { return GetSummary() },
+		},
+	}
+	for _, tree := range golden.Trees(t) {
+		for _, test := range tests {
+			t.Run(tree.Name+"/"+test.name, func(t *testing.T) {
+				content, err := test.getFrom(tree)
+				golden.Check(t, tree, test.name, content, err)
+			})
+			t.Run(tree.Name+"/PROC_ROOT/"+test.name, func(t *testing.T) {
+				tree.SetRoot(t)
+				content, err := test.getDefault()
+				golden.Check(t, tree, test.name, content, err)
+			})
+		}
+	}
+}
+
+// Single values in kB, including the HighTotal, LowTotal and CmaTotal that
+// only 32-bit ARM kernels write.
+func TestGetFrom(t *testing.T) {
+	meminfo, err := GetFrom(golden.Find(t, "synthetic-linux-5.10-raspbian-armv7l").FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		actual   uint64
+		expected uint64
+	}{
+		{"MemTotal", meminfo.MemTotal, 3748168},
+		{"HighTotal", meminfo.HighTotal, 3080192},
+		{"LowTotal", meminfo.LowTotal, 667976},
+		{"CmaTotal", meminfo.CmaTotal, 327680},
+	}
+	for _, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%s: got %d, want %d", test.name, test.actual, test.expected)
+		}
+	}
+}
+
+// Malformed lines give a ParseError in strict mode and a warning in lenient
+// mode; the other lines are still read.
+func TestGetFromMalformed(t *testing.T) {
+	tests := []golden.Malformed{
+		{
+			Name:     "value",
+			Data:     "MemTotal:       12x kB\nMemFree:          5 kB\n",
+			Expected: Meminfo{MemFree: 5, Extra: map[string]uint64{}, Units: map[string]string{"MemFree": "kB"}},
+			Wants:    []procfs.ParseError{{File: "meminfo", Line: 1, Field: "MemTotal", Text: "12x", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "value out of range",
+			Data:     "MemTotal:       7 kB\nNewField:       18446744073709551616\n",
+			Expected: Meminfo{MemTotal: 7, Extra: map[string]uint64{}, Units: map[string]string{"MemTotal": "kB"}},
+			Wants:    []procfs.ParseError{{File: "meminfo", Line: 2, Field: "NewField", Text: "18446744073709551616", Err: strconv.ErrRange}},
+		},
+		{
+			Name:     "short line",
+			Data:     "MemTotal:\nHugePages_Total:       2\n",
+			Expected: Meminfo{HugePages_Total: 2, Extra: map[string]uint64{}, Units: map[string]string{"HugePages_Total": ""}},
+			Wants:    []procfs.ParseError{{File: "meminfo", Line: 1, Text: "MemTotal:", Err: procfs.ErrShortLine}},
+		},
+	}
+	for _, test := range tests {
+		golden.CheckMalformed(t, "meminfo", test, func(fsys fs.FS) (interface{}, error) { return GetFrom(fsys) })
+	}
+}
+
+// Parse any meminfo without panicking, into a result or a ParseError, also
+// into the result of an earlier parse as a Parser does.
+func FuzzGetFrom(f *testing.F) {
+	golden.Seed(f, "meminfo")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tree := fstest.MapFS{
+			"meminfo":                {Data: data},
+			"sys/vm/min_free_kbytes": {Data: []byte("67584\n")},
+		}
+		for _, fsys := range golden.Modes(tree) {
+			result, err := GetFrom(fsys)
+			golden.CheckError(t, err)
+			golden.CheckError(t, ParseInto(fsys, data, &result))
+			_, err = GetAsMapFrom(fsys)
+			golden.CheckError(t, err)
+			_, err = GetSummaryFrom(fsys)
+			golden.CheckError(t, err)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package meminfo
+
+import (
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// Path of the minimum free memory the kernel keeps, in kB, relative to the
+// procfs root.
+const minFreeFilename = "sys/vm/min_free_kbytes"
+
+// Memory usage as reported by free(1), in bytes.  Cache includes reclaimable
+// slab.  Used is Total less Free, Buffers and Cache; free(1) of procps-ng 4.0
+// and later shows Total less Available instead.  AvailableEstimated is true if
+// the kernel does not export MemAvailable (before Linux 3.14) and Available
+// was estimated from the free, file and reclaimable slab memory instead.
+// DirtyRatio is the share of Total that is dirty or being written back, and
+// CommitRatio is Committed_AS over CommitLimit.
+// References:
+// - http://man7.org/linux/man-pages/man1/free.1.html
+type Summary struct {
+	Total              uint64  `json:"Total"`
+	Used               uint64  `json:"Used"`
+	Free               uint64  `json:"Free"`
+	Shared             uint64  `json:"Shared"`
+	Buffers            uint64  `json:"Buffers"`
+	Cache              uint64  `json:"Cache"`
+	Available          uint64  `json:"Available"`
+	AvailableEstimated bool    `json:"AvailableEstimated"`
+	SwapTotal          uint64  `json:"SwapTotal"`
+	SwapUsed           uint64  `json:"SwapUsed"`
+	SwapFree           uint64  `json:"SwapFree"`
+	DirtyRatio         float64 `json:"DirtyRatio"`
+	CommitRatio        float64 `json:"CommitRatio"`
+}
+
+func difference(a uint64, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
+
+func ratio(a uint64, b uint64) float64 {
+	if b == 0 {
+		return float64(0)
+	}
+	return float64(a) / float64(b)
+}
+
+// Summary of m.  If MemAvailable is missing, the low watermark of the kernel
+// is unknown, so Available is somewhat overestimated; use SummaryWithMinFree
+// or GetSummary to take it into account.
+// Example:
+//     myMeminfo, err := meminfo.Get()
+//     x := myMeminfo.Summary().Used
+func (m Meminfo) Summary() Summary {
+	return m.SummaryWithMinFree(0)
+}
+
+// Summary of m, given the value of /proc/sys/vm/min_free_kbytes.
+func (m Meminfo) SummaryWithMinFree(minFreeKbytes uint64) Summary {
+
+	result := Summary{
+		Total:     m.MemTotal * 1024,
+		Free:      m.MemFree * 1024,
+		Shared:    m.Shmem * 1024,
+		Buffers:   m.Buffers * 1024,
+		Cache:     (m.Cached + m.SReclaimable) * 1024,
+		Available: m.MemAvailable * 1024,
+		SwapTotal: m.SwapTotal * 1024,
+		SwapUsed:  difference(m.SwapTotal, m.SwapFree) * 1024,
+		SwapFree:  m.SwapFree * 1024,
+	}
+
+	// Like free(1), fall back to total - free if the page cache is larger than
+	// what is left.
+
+	used := difference(m.MemTotal, m.MemFree+m.Buffers+m.Cached+m.SReclaimable)
+	if used == 0 {
+		used = difference(m.MemTotal, m.MemFree)
+	}
+	result.Used = used * 1024
+
+	// Estimate MemAvailable the way the kernel computes it, with the low
+	// watermark approximated from min_free_kbytes as procps does.
+
+	if _, ok := m.Units["MemAvailable"]; !ok && m.MemAvailable == 0 {
+		lowWatermark := minFreeKbytes * 5 / 4
+		pageCache := m.Active_file + m.Inactive_file
+		available := difference(m.MemFree, lowWatermark)
+		available += pageCache - min(pageCache/2, lowWatermark)
+		available += m.SReclaimable - min(m.SReclaimable/2, lowWatermark)
+		result.Available = min(available, m.MemTotal) * 1024
+		result.AvailableEstimated = true
+	}
+
+	result.DirtyRatio = ratio(m.Dirty+m.Writeback, m.MemTotal)
+	result.CommitRatio = ratio(m.Committed_AS, m.CommitLimit)
+	return result
+}
+
+// Read /proc/sys/vm/min_free_kbytes from the procfs tree fsys.
+func getMinFreeFrom(fsys fs.FS) (uint64, error) {
+	data, err := procfs.ReadFile(fsys, minFreeFilename)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// Get the summary of /proc/meminfo.
+// Example:
+//     mySummary, err := meminfo.GetSummary()
+//     x := mySummary.Available
+func GetSummary() (Summary, error) {
+	return GetSummaryFrom(procfs.Default)
+}
+
+// Get the summary of meminfo from the procfs tree fsys.  sys/vm/min_free_kbytes
+// is read as well if MemAvailable has to be estimated.  Along with a
+// ParseError of a strict tree, it returns the summary of what could be parsed.
+func GetSummaryFrom(fsys fs.FS) (Summary, error) {
+	meminfo, err := GetFrom(fsys)
+	if err != nil {
+		return meminfo.Summary(), err
+	}
+	if _, ok := meminfo.Units["MemAvailable"]; ok {
+		return meminfo.Summary(), nil
+	}
+	minFree, _ := getMinFreeFrom(fsys) // Not readable in every container.
+	return meminfo.SummaryWithMinFree(minFree), nil
+}
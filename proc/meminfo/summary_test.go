@@ -0,0 +1,131 @@
+package meminfo
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// Used, the ratios and the estimate of MemAvailable, in kB before they are
+// converted to bytes.
+func TestSummaryWithMinFree(t *testing.T) {
+	available := map[string]string{"MemAvailable": "kB"}
+	tests := []struct {
+		name     string
+		meminfo  Meminfo
+		minFree  uint64
+		expected Summary
+	}{
+		{
+			name: "MemAvailable",
+			meminfo: Meminfo{
+				MemTotal: 1000, MemFree: 100, MemAvailable: 400, Buffers: 50, Cached: 300, SReclaimable: 50, Shmem: 10,
+				SwapTotal: 200, SwapFree: 150, Dirty: 5, Writeback: 5, CommitLimit: 600, Committed_AS: 300,
+				Units: available,
+			},
+			minFree: 4000,
+			expected: Summary{
+				Total: 1000 * 1024, Used: 500 * 1024, Free: 100 * 1024, Shared: 10 * 1024,
+				Buffers: 50 * 1024, Cache: 350 * 1024, Available: 400 * 1024,
+				SwapTotal: 200 * 1024, SwapUsed: 50 * 1024, SwapFree: 150 * 1024,
+				DirtyRatio: 0.01, CommitRatio: 0.5,
+			},
+		},
+		{
+			name:    "page cache larger than what is left",
+			meminfo: Meminfo{MemTotal: 1000, MemFree: 100, MemAvailable: 900, Cached: 950, Units: available},
+			expected: Summary{
+				Total: 1000 * 1024, Used: 900 * 1024, Free: 100 * 1024, Cache: 950 * 1024, Available: 900 * 1024,
+			},
+		},
+		{
+			name:    "MemAvailable of zero",
+			meminfo: Meminfo{MemTotal: 1000, MemFree: 10, Units: available},
+			expected: Summary{
+				Total: 1000 * 1024, Used: 990 * 1024, Free: 10 * 1024,
+			},
+		},
+		{
+			// The low watermark is 500: 500 of MemFree, 600-300 of the page
+			// cache and 100-50 of SReclaimable are available.
+			name:    "estimated",
+			meminfo: Meminfo{MemTotal: 4000, MemFree: 1000, Active_file: 400, Inactive_file: 200, Cached: 600, SReclaimable: 100},
+			minFree: 400,
+			expected: Summary{
+				Total: 4000 * 1024, Used: 2300 * 1024, Free: 1000 * 1024, Cache: 700 * 1024,
+				Available: 850 * 1024, AvailableEstimated: true,
+			},
+		},
+		{
+			name:    "estimated above MemTotal",
+			meminfo: Meminfo{MemTotal: 1000, MemFree: 800, Active_file: 300},
+			expected: Summary{
+				Total: 1000 * 1024, Used: 200 * 1024, Free: 800 * 1024,
+				Available: 1000 * 1024, AvailableEstimated: true,
+			},
+		},
+		{
+			name:    "watermark above MemFree",
+			meminfo: Meminfo{MemTotal: 1000, MemFree: 100, Active_file: 100, SReclaimable: 40},
+			minFree: 800,
+			expected: Summary{
+				Total: 1000 * 1024, Used: 860 * 1024, Free: 100 * 1024, Cache: 40 * 1024,
+				Available: 70 * 1024, AvailableEstimated: true,
+			},
+		},
+	}
+	for _, test := range tests {
+		summary := test.meminfo.SummaryWithMinFree(test.minFree)
+		if summary != test.expected {
+			t.Errorf("%s: got %+v, want %+v", test.name, summary, test.expected)
+		}
+	}
+}
+
+// min_free_kbytes is read only to estimate MemAvailable, and taken as 0 if it
+// cannot be read.
+func TestGetSummaryFrom(t *testing.T) {
+	const estimated = "MemTotal: 4000 kB\nMemFree: 1000 kB\nActive(file): 400 kB\nInactive(file): 200 kB\nSReclaimable: 100 kB\n"
+	tests := []struct {
+		name      string
+		meminfo   string
+		minFree   string
+		available uint64
+	}{
+		{"min_free_kbytes", estimated, "400\n", 850},
+		{"no min_free_kbytes", estimated, "", 1000 + 600 + 100},
+		{"malformed min_free_kbytes", estimated, "x\n", 1000 + 600 + 100},
+		{"MemAvailable", "MemTotal: 4000 kB\nMemAvailable: 123 kB\n", "400\n", 123},
+	}
+	for _, test := range tests {
+		tree := fstest.MapFS{"meminfo": {Data: []byte(test.meminfo)}}
+		if test.minFree != "" {
+			tree[minFreeFilename] = &fstest.MapFile{Data: []byte(test.minFree)}
+		}
+		summary, err := GetSummaryFrom(procfs.Strict(tree))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if summary.Available != test.available*1024 {
+			t.Errorf("%s: Available: got %d, want %d", test.name, summary.Available, test.available*1024)
+		}
+	}
+}
+
+// A strict tree returns the summary of what could be parsed along with the
+// ParseError.
+func TestGetSummaryFromMalformed(t *testing.T) {
+	tree := fstest.MapFS{"meminfo": {Data: []byte("MemTotal: 4000 kB\nMemFree: 1x kB\nMemAvailable: 3000 kB\n")}}
+	summary, err := GetSummaryFrom(procfs.Strict(tree))
+	var parseError *procfs.ParseError
+	if !errors.As(err, &parseError) || parseError.Field != "MemFree" || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("got %v, want a ParseError of MemFree", err)
+	}
+	expected := Summary{Total: 4000 * 1024, Used: 4000 * 1024, Available: 3000 * 1024}
+	if summary != expected {
+		t.Errorf("got %+v, want %+v", summary, expected)
+	}
+}
@@ -0,0 +1,51 @@
+package meminfo
+
+// Lines of /proc/meminfo that are counts rather than kB.
+var counts = map[string]bool{
+	"HugePages_Total": true,
+	"HugePages_Free":  true,
+	"HugePages_Rsvd":  true,
+	"HugePages_Surp":  true,
+}
+
+// Raw value of a line of /proc/meminfo, keyed like GetAsMap, e.g.
+// "Active(anon)".  Lines in Extra are included.
+func (m Meminfo) Value(key string) (uint64, bool) {
+	field := m.field(key)
+	if field == nil {
+		value, ok := m.Extra[key]
+		return value, ok
+	}
+	return *field, true
+}
+
+// Unit of a line of /proc/meminfo: "kB", or "" for a count such as
+// HugePages_Total.  The unit read from the file is used if there is one.
+func (m Meminfo) Unit(key string) string {
+	if unit, ok := m.Units[key]; ok {
+		return unit
+	}
+	if _, ok := m.Value(key); !ok || counts[key] {
+		return ""
+	}
+	return "kB"
+}
+
+// Whether a line of /proc/meminfo is a count rather than an amount of memory.
+func (m Meminfo) IsCount(key string) bool {
+	_, ok := m.Value(key)
+	return ok && m.Unit(key) == ""
+}
+
+// Value of a line of /proc/meminfo in bytes.  It is false for counts and
+// unknown keys.
+// Example:
+//     myMeminfo, err := meminfo.Get()
+//     x, _ := myMeminfo.Bytes("MemTotal")
+func (m Meminfo) Bytes(key string) (uint64, bool) {
+	value, ok := m.Value(key)
+	if !ok || m.Unit(key) != "kB" {
+		return 0, false
+	}
+	return value * 1024, true
+}
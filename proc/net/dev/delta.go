@@ -0,0 +1,110 @@
+package dev
+
+import (
+	"math"
+	"time"
+)
+
+// Per-second rates of an interface's counters between two samples of
+// /proc/net/dev.
+type Rate struct {
+	ReceiveBytes       float64 `json:"ReceiveBytes"`
+	ReceivePackets     float64 `json:"ReceivePackets"`
+	ReceiveErrs        float64 `json:"ReceiveErrs"`
+	ReceiveDrop        float64 `json:"ReceiveDrop"`
+	ReceiveFifo        float64 `json:"ReceiveFifo"`
+	ReceiveFrame       float64 `json:"ReceiveFrame"`
+	ReceiveCompressed  float64 `json:"ReceiveCompressed"`
+	ReceiveMulticast   float64 `json:"ReceiveMulticast"`
+	TransmitBytes      float64 `json:"TransmitBytes"`
+	TransmitPackets    float64 `json:"TransmitPackets"`
+	TransmitErrs       float64 `json:"TransmitErrs"`
+	TransmitDrop       float64 `json:"TransmitDrop"`
+	TransmitFifo       float64 `json:"TransmitFifo"`
+	TransmitColls      float64 `json:"TransmitColls"`
+	TransmitCarrier    float64 `json:"TransmitCarrier"`
+	TransmitCompressed float64 `json:"TransmitCompressed"`
+
+	// At least one counter went backwards by more than a wraparound explains,
+	// e.g. because the interface was re-created.  Such counters are assumed to
+	// have restarted from zero.
+	Reset bool `json:"Reset"`
+}
+
+type Rates map[string]Rate
+
+// The fastest a 32-bit counter is taken to count, in units per second: the
+// bytes of 1 Gbit/s.  Drivers of faster interfaces export 64-bit counters.
+const maxWrapRate = 125e6
+
+// Increase of a counter from prev to cur over seconds.  Drivers export either
+// 32-bit or 64-bit counters.  A counter that went backwards is taken to have
+// wrapped around 32 bits if prev and cur fit in 32 bits and the wrapped
+// increase is no more than maxWrapRate allows, i.e. prev was close to the
+// top of the range.  Otherwise it was reset, e.g. from 3000000000 to 1000 in
+// 5 seconds, and the increase is cur.  A 64-bit counter does not wrap.
+func counterDelta(prev uint64, cur uint64, seconds float64) (uint64, bool) {
+	if cur >= prev {
+		return cur - prev, false
+	}
+	if prev <= math.MaxUint32 {
+		wrapped := cur + (math.MaxUint32 - prev) + 1
+		if float64(wrapped) <= maxWrapRate*seconds {
+			return wrapped, false
+		}
+	}
+	return cur, true
+}
+
+// Compute per-second rates of each interface between two samples of
+// /proc/net/dev taken elapsed apart.  Interfaces present in only one of the
+// samples are left out.
+// Example:
+//     prev, _ := dev.Get()
+//     time.Sleep(5 * time.Second)
+//     cur, _ := dev.Get()
+//     x := dev.Delta(prev, cur, 5*time.Second)["eth0"].ReceiveBytes
+func Delta(prev Devs, cur Devs, elapsed time.Duration) Rates {
+
+	result := Rates{}
+	if elapsed <= 0 {
+		return result
+	}
+	seconds := elapsed.Seconds()
+
+	for key, curDev := range cur {
+		prevDev, ok := prev[key]
+		if !ok {
+			continue
+		}
+
+		reset := false
+		rate := func(prev uint64, cur uint64) float64 {
+			delta, wasReset := counterDelta(prev, cur, seconds)
+			reset = reset || wasReset
+			return float64(delta) / seconds
+		}
+
+		aRate := Rate{
+			ReceiveBytes:       rate(prevDev.ReceiveBytes, curDev.ReceiveBytes),
+			ReceivePackets:     rate(prevDev.ReceivePackets, curDev.ReceivePackets),
+			ReceiveErrs:        rate(prevDev.ReceiveErrs, curDev.ReceiveErrs),
+			ReceiveDrop:        rate(prevDev.ReceiveDrop, curDev.ReceiveDrop),
+			ReceiveFifo:        rate(prevDev.ReceiveFifo, curDev.ReceiveFifo),
+			ReceiveFrame:       rate(prevDev.ReceiveFrame, curDev.ReceiveFrame),
+			ReceiveCompressed:  rate(prevDev.ReceiveCompressed, curDev.ReceiveCompressed),
+			ReceiveMulticast:   rate(prevDev.ReceiveMulticast, curDev.ReceiveMulticast),
+			TransmitBytes:      rate(prevDev.TransmitBytes, curDev.TransmitBytes),
+			TransmitPackets:    rate(prevDev.TransmitPackets, curDev.TransmitPackets),
+			TransmitErrs:       rate(prevDev.TransmitErrs, curDev.TransmitErrs),
+			TransmitDrop:       rate(prevDev.TransmitDrop, curDev.TransmitDrop),
+			TransmitFifo:       rate(prevDev.TransmitFifo, curDev.TransmitFifo),
+			TransmitColls:      rate(prevDev.TransmitColls, curDev.TransmitColls),
+			TransmitCarrier:    rate(prevDev.TransmitCarrier, curDev.TransmitCarrier),
+			TransmitCompressed: rate(prevDev.TransmitCompressed, curDev.TransmitCompressed),
+		}
+		aRate.Reset = reset
+		result[key] = aRate
+	}
+	return result
+}
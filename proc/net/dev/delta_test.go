@@ -0,0 +1,61 @@
+package dev
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCounterDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur uint64
+		seconds   float64
+		delta     uint64
+		reset     bool
+	}{
+		{"increase", 1000, 6000, 5, 5000, false},
+		{"unchanged", 1000, 1000, 5, 0, false},
+		{"32-bit wrap", math.MaxUint32 - 99, 50, 5, 150, false},
+		{"32-bit wrap at the rate ceiling", math.MaxUint32 - 99, maxWrapRate - 100, 1, maxWrapRate, false},
+		{"reset above the rate ceiling", math.MaxUint32 - 99, maxWrapRate - 99, 1, maxWrapRate - 99, true},
+		{"reset from a high value", 3000000000, 1000, 5, 1000, true},
+		{"reset to zero", 3000000000, 0, 5, 0, true},
+		{"64-bit decrease", 5000000000000, 4000000000000, 5, 4000000000000, true},
+		{"64-bit to 32-bit decrease", math.MaxUint32 + 1, 10, 5, 10, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			delta, reset := counterDelta(test.prev, test.cur, test.seconds)
+			if delta != test.delta || reset != test.reset {
+				t.Errorf("counterDelta(%d, %d, %g) = %d, %t; want %d, %t", test.prev, test.cur, test.seconds, delta, reset, test.delta, test.reset)
+			}
+		})
+	}
+}
+
+func TestDelta(t *testing.T) {
+	prev := Devs{
+		"eth0": {ReceiveBytes: 1000, TransmitBytes: math.MaxUint32 - 99},
+		"eth1": {ReceiveBytes: 3000000000},
+		"gone": {ReceiveBytes: 1},
+	}
+	cur := Devs{
+		"eth0": {ReceiveBytes: 11000, TransmitBytes: 400},
+		"eth1": {ReceiveBytes: 1000},
+		"new":  {ReceiveBytes: 1},
+	}
+	expected := Rates{
+		"eth0": {ReceiveBytes: 1000, TransmitBytes: 50},
+		"eth1": {ReceiveBytes: 100, Reset: true},
+	}
+	if actual := Delta(prev, cur, 10*time.Second); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Delta = %+v; want %+v", actual, expected)
+	}
+	for _, elapsed := range []time.Duration{0, -time.Second} {
+		if actual := Delta(prev, cur, elapsed); len(actual) != 0 {
+			t.Errorf("Delta over %v = %+v; want no rates", elapsed, actual)
+		}
+	}
+}
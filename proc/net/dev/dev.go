@@ -1,24 +1,28 @@
 package dev
 
 import (
-	"bufio"
 	"encoding/json"
-	"os"
-	"strconv"
+	"errors"
+	"io/fs"
 	"strings"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
 )
 
+// Path of the file relative to the procfs root.
+const filename = "net/dev"
+
 // References:
 // - http://man7.org/linux/man-pages/man5/proc.5.html  "/proc/net/dev"
 type Dev struct {
 	ReceiveBytes       uint64 `json:"ReceiveBytes"`
 	ReceivePackets     uint64 `json:"ReceivePackets"`
 	ReceiveErrs        uint64 `json:"ReceiveErrs"`
-	ReceiveDrop        uint64 `json:"ReceiveErrs"`
+	ReceiveDrop        uint64 `json:"ReceiveDrop"`
 	ReceiveFifo        uint64 `json:"ReceiveFifo"`
 	ReceiveFrame       uint64 `json:"ReceiveFrame"`
 	ReceiveCompressed  uint64 `json:"ReceiveCompressed"`
-	ReceiveMulticast   uint64 `json:"ReceiveCompressed"`
+	ReceiveMulticast   uint64 `json:"ReceiveMulticast"`
 	TransmitBytes      uint64 `json:"TransmitBytes"`
 	TransmitPackets    uint64 `json:"TransmitPackets"`
 	TransmitErrs       uint64 `json:"TransmitErrs"`
@@ -31,151 +35,152 @@ type Dev struct {
 
 type Devs map[string]Dev
 
-// Allow filename to be specified by OS Environment variable: PROC_NET_SNMP
+// Allow filename to be specified by OS Environment variable: PROC_NET_DEV
 func GetFilename() string {
-	result := os.Getenv("PROC_NET_DEV")
-	if result == "" {
-		result = "/proc/net/dev"
+	return procfs.Filename(filename)
+}
+
+var errNoColon = errors.New("missing \":\" after the interface")
+
+// Parse a line of net/dev such as "  eth0: 1234 5 0 ...".  Old kernels
+// leave no space after the colon.
+func parseLine(check *procfs.Checker, inputLine string) (string, Dev, bool) {
+	key, values, found := strings.Cut(inputLine, ":")
+	if !found {
+		check.Report("", inputLine, errNoColon)
+		return "", Dev{}, false
 	}
-	return result
+	splits := strings.Fields(values)
+	if !check.Fields(inputLine, splits, 16) {
+		return "", Dev{}, false
+	}
+	aDev := Dev{
+		ReceiveBytes:       check.Uint64("ReceiveBytes", splits[0], 10),
+		ReceivePackets:     check.Uint64("ReceivePackets", splits[1], 10),
+		ReceiveErrs:        check.Uint64("ReceiveErrs", splits[2], 10),
+		ReceiveDrop:        check.Uint64("ReceiveDrop", splits[3], 10),
+		ReceiveFifo:        check.Uint64("ReceiveFifo", splits[4], 10),
+		ReceiveFrame:       check.Uint64("ReceiveFrame", splits[5], 10),
+		ReceiveCompressed:  check.Uint64("ReceiveCompressed", splits[6], 10),
+		ReceiveMulticast:   check.Uint64("ReceiveMulticast", splits[7], 10),
+		TransmitBytes:      check.Uint64("TransmitBytes", splits[8], 10),
+		TransmitPackets:    check.Uint64("TransmitPackets", splits[9], 10),
+		TransmitErrs:       check.Uint64("TransmitErrs", splits[10], 10),
+		TransmitDrop:       check.Uint64("TransmitDrop", splits[11], 10),
+		TransmitFifo:       check.Uint64("TransmitFifo", splits[12], 10),
+		TransmitColls:      check.Uint64("TransmitColls", splits[13], 10),
+		TransmitCarrier:    check.Uint64("TransmitCarrier", splits[14], 10),
+		TransmitCompressed: check.Uint64("TransmitCompressed", splits[15], 10),
+	}
+	return strings.TrimSpace(key), aDev, true
 }
 
 func Get() (Devs, error) {
+	return GetFrom(procfs.Default)
+}
+
+// Get values of net/dev from the procfs tree fsys.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     myDevs, err := dev.GetFrom(myFS)
+func GetFrom(fsys fs.FS) (Devs, error) {
 
 	result := Devs{}
 
-	// Open the file.
+	// Read the file in a single read.
 
-	fileName := GetFilename()
-	file, err := os.Open(fileName)
+	data, err := procfs.ReadFile(fsys, filename)
 	if err != nil {
 		return result, err
 	}
 
-	// Read the file.
+	// Parse the file.
 
-	scanner := bufio.NewScanner(file)
+	check := procfs.NewChecker(fsys, filename)
+	scanner := procfs.NewScanner(data)
 	for scanner.Scan() {
+		check.NextLine()
 		inputLine := scanner.Text()
-		if strings.Contains(inputLine, "|") { // Avoid table headers.
+		if strings.Contains(inputLine, "|") || strings.TrimSpace(inputLine) == "" { // Avoid table headers.
 			continue
 		}
-
-		splits := strings.Fields(inputLine)
-
-		// Pull out the key.
-
-		keySplits := strings.Split(splits[0], ":")
-		key := keySplits[0]
-
-		// Convert strings to uint64.
-
-		uint64splits := make([]uint64, len(splits))
-		for index, split := range splits {
-			value, err := strconv.ParseUint(split, 10, 64)
-			if err != nil {
-				continue
-			}
-			uint64splits[index] = value
-		}
-
-		// Pull out the values.
-
-		aDev := Dev{
-			ReceiveBytes:       uint64splits[1],
-			ReceivePackets:     uint64splits[2],
-			ReceiveErrs:        uint64splits[3],
-			ReceiveDrop:        uint64splits[4],
-			ReceiveFifo:        uint64splits[5],
-			ReceiveFrame:       uint64splits[6],
-			ReceiveCompressed:  uint64splits[7],
-			ReceiveMulticast:   uint64splits[8],
-			TransmitBytes:      uint64splits[9],
-			TransmitPackets:    uint64splits[10],
-			TransmitErrs:       uint64splits[11],
-			TransmitDrop:       uint64splits[12],
-			TransmitFifo:       uint64splits[13],
-			TransmitColls:      uint64splits[14],
-			TransmitCarrier:    uint64splits[15],
-			TransmitCompressed: uint64splits[16],
+		key, aDev, ok := parseLine(check, inputLine)
+		if !ok {
+			continue
 		}
 		result[key] = aDev
 	}
-	return result, nil
-
+	if err := scanner.Err(); err != nil {
+		return result, err
+	}
+	return result, check.Err()
 }
 
 func GetAsJson() ([]byte, error) {
-	content, err := Get()
+	return GetAsJsonFrom(procfs.Default)
+}
+
+func GetAsJsonFrom(fsys fs.FS) ([]byte, error) {
+	content, err := GetFrom(fsys)
 	if err != nil {
 		return []byte{}, err
 	}
-	result, _ := json.Marshal(content)
-	return result, nil
+	return json.Marshal(content)
 }
 
-// Get values of /proc/net/dev as a map of maps of uint64.
+// Get values of /proc/net/dev as JSON with the keys of struct fields in the case
+// keys.  Keys of maps are left as they are.
 // Example:
-//     myDev := dev.Get()
-//     x := myDev["lo"]["receive-bytes"]
-func GetAsMap() (map[string]map[string]uint64, error) {
-
-	result := make(map[string]map[string]uint64)
-
-	// Open the file.
+//     myJson, err := dev.GetAsJsonKeys(proc.SnakeCase) // {"lo":{"receive_bytes":...}}
+func GetAsJsonKeys(keys procfs.KeyCase) ([]byte, error) {
+	return GetAsJsonKeysFrom(procfs.Default, keys)
+}
 
-	fileName := GetFilename()
-	file, err := os.Open(fileName)
+func GetAsJsonKeysFrom(fsys fs.FS, keys procfs.KeyCase) ([]byte, error) {
+	content, err := GetFrom(fsys)
 	if err != nil {
-		return result, err
+		return []byte{}, err
 	}
+	return procfs.MarshalJSON(content, keys)
+}
 
-	// Read the file.
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		inputLine := scanner.Text()
-		if strings.Contains(inputLine, "|") { // Avoid table headers.
-			continue
-		}
-
-		splits := strings.Fields(inputLine)
-
-		// Pull out the key.
-
-		keySplits := strings.Split(splits[0], ":")
-		key := keySplits[0]
-		result[key] = make(map[string]uint64)
+// Get values of /proc/net/dev as a map of maps of uint64.
+// Example:
+//     myDev := dev.GetAsMap()
+//     x := myDev["lo"]["ReceiveBytes"]
+func GetAsMap() (map[string]map[string]uint64, error) {
+	return GetAsMapFrom(procfs.Default)
+}
 
-		// Convert strings to uint64.
+// Get values of net/dev from the procfs tree fsys as a map of maps of uint64.
+// The map is built from the same read of the file as Get.
+func GetAsMapFrom(fsys fs.FS) (map[string]map[string]uint64, error) {
+	devs, err := GetFrom(fsys)
+	return devs.AsMap(), err
+}
 
-		uint64splits := make([]uint64, len(splits))
-		for index, split := range splits {
-			value, err := strconv.ParseUint(split, 10, 64)
-			if err != nil {
-				continue
-			}
-			uint64splits[index] = value
+// Values of d as a map of maps of uint64 keyed by interface.
+func (d Devs) AsMap() map[string]map[string]uint64 {
+	result := make(map[string]map[string]uint64)
+	for key, aDev := range d {
+		result[key] = map[string]uint64{
+			"ReceiveBytes":       aDev.ReceiveBytes,
+			"ReceivePackets":     aDev.ReceivePackets,
+			"ReceiveErrs":        aDev.ReceiveErrs,
+			"ReceiveDrop":        aDev.ReceiveDrop,
+			"ReceiveFifo":        aDev.ReceiveFifo,
+			"ReceiveFrame":       aDev.ReceiveFrame,
+			"ReceiveCompressed":  aDev.ReceiveCompressed,
+			"ReceiveMulticast":   aDev.ReceiveMulticast,
+			"TransmitBytes":      aDev.TransmitBytes,
+			"TransmitPackets":    aDev.TransmitPackets,
+			"TransmitErrs":       aDev.TransmitErrs,
+			"TransmitDrop":       aDev.TransmitDrop,
+			"TransmitFifo":       aDev.TransmitFifo,
+			"TransmitColls":      aDev.TransmitColls,
+			"TransmitCarrier":    aDev.TransmitCarrier,
+			"TransmitCompressed": aDev.TransmitCompressed,
 		}
-
-		// Pull out the values.
-
-		result[key]["ReceiveBytes"] = uint64splits[1]
-		result[key]["ReceivePackets"] = uint64splits[2]
-		result[key]["ReceiveErrs"] = uint64splits[3]
-		result[key]["ReceiveDrop"] = uint64splits[4]
-		result[key]["ReceiveFifo"] = uint64splits[5]
-		result[key]["ReceiveFrame"] = uint64splits[6]
-		result[key]["ReceiveCompressed"] = uint64splits[7]
-		result[key]["ReceiveMulticast"] = uint64splits[8]
-		result[key]["TransmitBytes"] = uint64splits[9]
-		result[key]["TransmitPackets"] = uint64splits[10]
-		result[key]["TransmitErrs"] = uint64splits[11]
-		result[key]["TransmitDrop"] = uint64splits[12]
-		result[key]["TransmitFifo"] = uint64splits[13]
-		result[key]["TransmitColls"] = uint64splits[14]
-		result[key]["TransmitCarrier"] = uint64splits[15]
-		result[key]["TransmitCompressed"] = uint64splits[16]
 	}
-	return result, nil
+	return result
 }
@@ -0,0 +1,102 @@
+package dev
+
+import (
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"io/fs"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// The trees under testdata/, read from an fs.FS and, through PROC_ROOT, from
+// the default procfs tree.
+func TestGolden(t *testing.T) {
+	for _, tree := range golden.Trees(t) {
+		t.Run(tree.Name, func(t *testing.T) {
+			content, err := GetFrom(tree.FS)
+			golden.Check(t, tree, "net_dev", content, err)
+		})
+		t.Run(tree.Name+"/PROC_ROOT", func(t *testing.T) {
+			tree.SetRoot(t)
+			content, err := Get()
+			golden.Check(t, tree, "net_dev", content, err)
+		})
+	}
+}
+
+// Single values, including a device with no space after its colon, as old
+// kernels write them.
+func TestGetFrom(t *testing.T) {
+	tests := []struct {
+		tree     string
+		device   string
+		expected Dev
+	}{
+		{"linux-6.18-container-x86_64", "lo", Dev{
+			ReceiveBytes: 55664470, ReceivePackets: 6593,
+			TransmitBytes: 55664470, TransmitPackets: 6593,
+		}},
+		{"linux-6.18-container-x86_64", "eth0", Dev{
+			ReceiveBytes: 3744, ReceivePackets: 57,
+			TransmitBytes: 5215, TransmitPackets: 59,
+		}},
+		{"synthetic-linux-3.10-centos7-x86_64", "eth0", Dev{
+			ReceiveBytes: 12345678901, ReceivePackets: 9234123,
+			TransmitBytes: 987654321, TransmitPackets: 5312004,
+		}},
+	}
+	for _, test := range tests {
+		devs, err := GetFrom(golden.Find(t, test.tree).FS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if devs[test.device] != test.expected {
+			t.Errorf("%s: %s: got %+v, want %+v", test.tree, test.device, devs[test.device], test.expected)
+		}
+	}
+}
+
+// Malformed lines give a ParseError in strict mode and a warning in lenient
+// mode; the other lines are still read.
+func TestGetFromMalformed(t *testing.T) {
+	const header = "Inter-|   Receive                                                |  Transmit\n" +
+		" face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed\n"
+	tests := []golden.Malformed{
+		{
+			Name:     "value",
+			Data:     header + "  eth0: 12x 3 0 0 0 0 0 0 4 5 0 0 0 0 0 0\n",
+			Expected: Devs{"eth0": {ReceivePackets: 3, TransmitBytes: 4, TransmitPackets: 5}},
+			Wants:    []procfs.ParseError{{File: "net/dev", Line: 3, Field: "ReceiveBytes", Text: "12x", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "short line",
+			Data:     header + "  eth0: 1 2 3\n    lo: 6 7 0 0 0 0 0 0 6 7 0 0 0 0 0 0\n",
+			Expected: Devs{"lo": {ReceiveBytes: 6, ReceivePackets: 7, TransmitBytes: 6, TransmitPackets: 7}},
+			Wants:    []procfs.ParseError{{File: "net/dev", Line: 3, Text: "  eth0: 1 2 3", Err: procfs.ErrShortLine}},
+		},
+		{
+			Name:     "no colon",
+			Data:     header + "  eth0 1 2 0 0 0 0 0 0 1 2 0 0 0 0 0 0\n",
+			Expected: Devs{},
+			Wants:    []procfs.ParseError{{File: "net/dev", Line: 3, Text: "  eth0 1 2 0 0 0 0 0 0 1 2 0 0 0 0 0 0", Err: errNoColon}},
+		},
+	}
+	for _, test := range tests {
+		golden.CheckMalformed(t, "net/dev", test, func(fsys fs.FS) (interface{}, error) { return GetFrom(fsys) })
+	}
+}
+
+// Parse any net/dev without panicking, into a result or a ParseError.
+func FuzzGetFrom(f *testing.F) {
+	golden.Seed(f, "net/dev")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, fsys := range golden.Modes(fstest.MapFS{"net/dev": {Data: data}}) {
+			_, err := GetFrom(fsys)
+			golden.CheckError(t, err)
+			_, err = GetAsMapFrom(fsys)
+			golden.CheckError(t, err)
+		}
+	})
+}
@@ -0,0 +1,511 @@
+package netstat
+
+import (
+	"encoding/json"
+	"io/fs"
+	"strconv"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// Counters of sections other than TcpExt and IpExt (e.g. MPTcpExt), and
+// counters of TcpExt and IpExt not known to this package, are kept in Extra.
+// References:
+// - http://man7.org/linux/man-pages/man5/proc.5.html  "/proc/net/netstat"
+// - https://www.kernel.org/doc/html/latest/networking/snmp_counter.html
+type Netstat struct {
+	TcpExt TcpExt                       `json:"TcpExt"`
+	IpExt  IpExt                        `json:"IpExt"`
+	Extra  map[string]map[string]uint64 `json:"Extra"`
+}
+
+type TcpExt struct {
+	SyncookiesSent            uint64 `json:"SyncookiesSent"`
+	SyncookiesRecv            uint64 `json:"SyncookiesRecv"`
+	SyncookiesFailed          uint64 `json:"SyncookiesFailed"`
+	EmbryonicRsts             uint64 `json:"EmbryonicRsts"`
+	PruneCalled               uint64 `json:"PruneCalled"`
+	RcvPruned                 uint64 `json:"RcvPruned"`
+	OfoPruned                 uint64 `json:"OfoPruned"`
+	OutOfWindowIcmps          uint64 `json:"OutOfWindowIcmps"`
+	LockDroppedIcmps          uint64 `json:"LockDroppedIcmps"`
+	ArpFilter                 uint64 `json:"ArpFilter"`
+	TW                        uint64 `json:"TW"`
+	TWRecycled                uint64 `json:"TWRecycled"`
+	TWKilled                  uint64 `json:"TWKilled"`
+	PAWSActive                uint64 `json:"PAWSActive"`
+	PAWSEstab                 uint64 `json:"PAWSEstab"`
+	BeyondWindow              uint64 `json:"BeyondWindow"`
+	TSEcrRejected             uint64 `json:"TSEcrRejected"`
+	PAWSOldAck                uint64 `json:"PAWSOldAck"`
+	PAWSTimewait              uint64 `json:"PAWSTimewait"`
+	DelayedACKs               uint64 `json:"DelayedACKs"`
+	DelayedACKLocked          uint64 `json:"DelayedACKLocked"`
+	DelayedACKLost            uint64 `json:"DelayedACKLost"`
+	ListenOverflows           uint64 `json:"ListenOverflows"`
+	ListenDrops               uint64 `json:"ListenDrops"`
+	TCPHPHits                 uint64 `json:"TCPHPHits"`
+	TCPPureAcks               uint64 `json:"TCPPureAcks"`
+	TCPHPAcks                 uint64 `json:"TCPHPAcks"`
+	TCPRenoRecovery           uint64 `json:"TCPRenoRecovery"`
+	TCPSackRecovery           uint64 `json:"TCPSackRecovery"`
+	TCPSACKReneging           uint64 `json:"TCPSACKReneging"`
+	TCPSACKReorder            uint64 `json:"TCPSACKReorder"`
+	TCPRenoReorder            uint64 `json:"TCPRenoReorder"`
+	TCPTSReorder              uint64 `json:"TCPTSReorder"`
+	TCPFullUndo               uint64 `json:"TCPFullUndo"`
+	TCPPartialUndo            uint64 `json:"TCPPartialUndo"`
+	TCPDSACKUndo              uint64 `json:"TCPDSACKUndo"`
+	TCPLossUndo               uint64 `json:"TCPLossUndo"`
+	TCPLostRetransmit         uint64 `json:"TCPLostRetransmit"`
+	TCPRenoFailures           uint64 `json:"TCPRenoFailures"`
+	TCPSackFailures           uint64 `json:"TCPSackFailures"`
+	TCPLossFailures           uint64 `json:"TCPLossFailures"`
+	TCPFastRetrans            uint64 `json:"TCPFastRetrans"`
+	TCPSlowStartRetrans       uint64 `json:"TCPSlowStartRetrans"`
+	TCPTimeouts               uint64 `json:"TCPTimeouts"`
+	TCPLossProbes             uint64 `json:"TCPLossProbes"`
+	TCPLossProbeRecovery      uint64 `json:"TCPLossProbeRecovery"`
+	TCPRenoRecoveryFail       uint64 `json:"TCPRenoRecoveryFail"`
+	TCPSackRecoveryFail       uint64 `json:"TCPSackRecoveryFail"`
+	TCPRcvCollapsed           uint64 `json:"TCPRcvCollapsed"`
+	TCPBacklogCoalesce        uint64 `json:"TCPBacklogCoalesce"`
+	TCPDSACKOldSent           uint64 `json:"TCPDSACKOldSent"`
+	TCPDSACKOfoSent           uint64 `json:"TCPDSACKOfoSent"`
+	TCPDSACKRecv              uint64 `json:"TCPDSACKRecv"`
+	TCPDSACKOfoRecv           uint64 `json:"TCPDSACKOfoRecv"`
+	TCPAbortOnData            uint64 `json:"TCPAbortOnData"`
+	TCPAbortOnClose           uint64 `json:"TCPAbortOnClose"`
+	TCPAbortOnMemory          uint64 `json:"TCPAbortOnMemory"`
+	TCPAbortOnTimeout         uint64 `json:"TCPAbortOnTimeout"`
+	TCPAbortOnLinger          uint64 `json:"TCPAbortOnLinger"`
+	TCPAbortFailed            uint64 `json:"TCPAbortFailed"`
+	TCPMemoryPressures        uint64 `json:"TCPMemoryPressures"`
+	TCPMemoryPressuresChrono  uint64 `json:"TCPMemoryPressuresChrono"`
+	TCPSACKDiscard            uint64 `json:"TCPSACKDiscard"`
+	TCPDSACKIgnoredOld        uint64 `json:"TCPDSACKIgnoredOld"`
+	TCPDSACKIgnoredNoUndo     uint64 `json:"TCPDSACKIgnoredNoUndo"`
+	TCPSpuriousRTOs           uint64 `json:"TCPSpuriousRTOs"`
+	TCPMD5NotFound            uint64 `json:"TCPMD5NotFound"`
+	TCPMD5Unexpected          uint64 `json:"TCPMD5Unexpected"`
+	TCPMD5Failure             uint64 `json:"TCPMD5Failure"`
+	TCPSackShifted            uint64 `json:"TCPSackShifted"`
+	TCPSackMerged             uint64 `json:"TCPSackMerged"`
+	TCPSackShiftFallback      uint64 `json:"TCPSackShiftFallback"`
+	TCPBacklogDrop            uint64 `json:"TCPBacklogDrop"`
+	PFMemallocDrop            uint64 `json:"PFMemallocDrop"`
+	TCPMinTTLDrop             uint64 `json:"TCPMinTTLDrop"`
+	TCPDeferAcceptDrop        uint64 `json:"TCPDeferAcceptDrop"`
+	IPReversePathFilter       uint64 `json:"IPReversePathFilter"`
+	TCPTimeWaitOverflow       uint64 `json:"TCPTimeWaitOverflow"`
+	TCPReqQFullDoCookies      uint64 `json:"TCPReqQFullDoCookies"`
+	TCPReqQFullDrop           uint64 `json:"TCPReqQFullDrop"`
+	TCPRetransFail            uint64 `json:"TCPRetransFail"`
+	TCPRcvCoalesce            uint64 `json:"TCPRcvCoalesce"`
+	TCPOFOQueue               uint64 `json:"TCPOFOQueue"`
+	TCPOFODrop                uint64 `json:"TCPOFODrop"`
+	TCPOFOMerge               uint64 `json:"TCPOFOMerge"`
+	TCPChallengeACK           uint64 `json:"TCPChallengeACK"`
+	TCPSYNChallenge           uint64 `json:"TCPSYNChallenge"`
+	TCPFastOpenActive         uint64 `json:"TCPFastOpenActive"`
+	TCPFastOpenActiveFail     uint64 `json:"TCPFastOpenActiveFail"`
+	TCPFastOpenPassive        uint64 `json:"TCPFastOpenPassive"`
+	TCPFastOpenPassiveFail    uint64 `json:"TCPFastOpenPassiveFail"`
+	TCPFastOpenListenOverflow uint64 `json:"TCPFastOpenListenOverflow"`
+	TCPFastOpenCookieReqd     uint64 `json:"TCPFastOpenCookieReqd"`
+	TCPFastOpenBlackhole      uint64 `json:"TCPFastOpenBlackhole"`
+	TCPSpuriousRtxHostQueues  uint64 `json:"TCPSpuriousRtxHostQueues"`
+	BusyPollRxPackets         uint64 `json:"BusyPollRxPackets"`
+	TCPAutoCorking            uint64 `json:"TCPAutoCorking"`
+	TCPFromZeroWindowAdv      uint64 `json:"TCPFromZeroWindowAdv"`
+	TCPToZeroWindowAdv        uint64 `json:"TCPToZeroWindowAdv"`
+	TCPWantZeroWindowAdv      uint64 `json:"TCPWantZeroWindowAdv"`
+	TCPSynRetrans             uint64 `json:"TCPSynRetrans"`
+	TCPOrigDataSent           uint64 `json:"TCPOrigDataSent"`
+	TCPKeepAlive              uint64 `json:"TCPKeepAlive"`
+	TCPDelivered              uint64 `json:"TCPDelivered"`
+	TCPDeliveredCE            uint64 `json:"TCPDeliveredCE"`
+}
+
+type IpExt struct {
+	InNoRoutes      uint64 `json:"InNoRoutes"`
+	InTruncatedPkts uint64 `json:"InTruncatedPkts"`
+	InMcastPkts     uint64 `json:"InMcastPkts"`
+	OutMcastPkts    uint64 `json:"OutMcastPkts"`
+	InBcastPkts     uint64 `json:"InBcastPkts"`
+	OutBcastPkts    uint64 `json:"OutBcastPkts"`
+	InOctets        uint64 `json:"InOctets"`
+	OutOctets       uint64 `json:"OutOctets"`
+	InMcastOctets   uint64 `json:"InMcastOctets"`
+	OutMcastOctets  uint64 `json:"OutMcastOctets"`
+	InBcastOctets   uint64 `json:"InBcastOctets"`
+	OutBcastOctets  uint64 `json:"OutBcastOctets"`
+	InCsumErrors    uint64 `json:"InCsumErrors"`
+	InNoECTPkts     uint64 `json:"InNoECTPkts"`
+	InECT1Pkts      uint64 `json:"InECT1Pkts"`
+	InECT0Pkts      uint64 `json:"InECT0Pkts"`
+	InCEPkts        uint64 `json:"InCEPkts"`
+	ReasmOverlaps   uint64 `json:"ReasmOverlaps"`
+}
+
+// Path of the file relative to the procfs root.
+const filename = "net/netstat"
+
+// Allow filename to be specified by OS Environment variable: PROC_NET_NETSTAT
+func GetFilename() string {
+	return procfs.Filename(filename)
+}
+
+// Set the field named key, reporting whether there is one.
+func (s *TcpExt) set(key string, value uint64) bool {
+	switch key {
+	case "SyncookiesSent":
+		s.SyncookiesSent = value
+	case "SyncookiesRecv":
+		s.SyncookiesRecv = value
+	case "SyncookiesFailed":
+		s.SyncookiesFailed = value
+	case "EmbryonicRsts":
+		s.EmbryonicRsts = value
+	case "PruneCalled":
+		s.PruneCalled = value
+	case "RcvPruned":
+		s.RcvPruned = value
+	case "OfoPruned":
+		s.OfoPruned = value
+	case "OutOfWindowIcmps":
+		s.OutOfWindowIcmps = value
+	case "LockDroppedIcmps":
+		s.LockDroppedIcmps = value
+	case "ArpFilter":
+		s.ArpFilter = value
+	case "TW":
+		s.TW = value
+	case "TWRecycled":
+		s.TWRecycled = value
+	case "TWKilled":
+		s.TWKilled = value
+	case "PAWSActive":
+		s.PAWSActive = value
+	case "PAWSEstab":
+		s.PAWSEstab = value
+	case "BeyondWindow":
+		s.BeyondWindow = value
+	case "TSEcrRejected":
+		s.TSEcrRejected = value
+	case "PAWSOldAck":
+		s.PAWSOldAck = value
+	case "PAWSTimewait":
+		s.PAWSTimewait = value
+	case "DelayedACKs":
+		s.DelayedACKs = value
+	case "DelayedACKLocked":
+		s.DelayedACKLocked = value
+	case "DelayedACKLost":
+		s.DelayedACKLost = value
+	case "ListenOverflows":
+		s.ListenOverflows = value
+	case "ListenDrops":
+		s.ListenDrops = value
+	case "TCPHPHits":
+		s.TCPHPHits = value
+	case "TCPPureAcks":
+		s.TCPPureAcks = value
+	case "TCPHPAcks":
+		s.TCPHPAcks = value
+	case "TCPRenoRecovery":
+		s.TCPRenoRecovery = value
+	case "TCPSackRecovery":
+		s.TCPSackRecovery = value
+	case "TCPSACKReneging":
+		s.TCPSACKReneging = value
+	case "TCPSACKReorder":
+		s.TCPSACKReorder = value
+	case "TCPRenoReorder":
+		s.TCPRenoReorder = value
+	case "TCPTSReorder":
+		s.TCPTSReorder = value
+	case "TCPFullUndo":
+		s.TCPFullUndo = value
+	case "TCPPartialUndo":
+		s.TCPPartialUndo = value
+	case "TCPDSACKUndo":
+		s.TCPDSACKUndo = value
+	case "TCPLossUndo":
+		s.TCPLossUndo = value
+	case "TCPLostRetransmit":
+		s.TCPLostRetransmit = value
+	case "TCPRenoFailures":
+		s.TCPRenoFailures = value
+	case "TCPSackFailures":
+		s.TCPSackFailures = value
+	case "TCPLossFailures":
+		s.TCPLossFailures = value
+	case "TCPFastRetrans":
+		s.TCPFastRetrans = value
+	case "TCPSlowStartRetrans":
+		s.TCPSlowStartRetrans = value
+	case "TCPTimeouts":
+		s.TCPTimeouts = value
+	case "TCPLossProbes":
+		s.TCPLossProbes = value
+	case "TCPLossProbeRecovery":
+		s.TCPLossProbeRecovery = value
+	case "TCPRenoRecoveryFail":
+		s.TCPRenoRecoveryFail = value
+	case "TCPSackRecoveryFail":
+		s.TCPSackRecoveryFail = value
+	case "TCPRcvCollapsed":
+		s.TCPRcvCollapsed = value
+	case "TCPBacklogCoalesce":
+		s.TCPBacklogCoalesce = value
+	case "TCPDSACKOldSent":
+		s.TCPDSACKOldSent = value
+	case "TCPDSACKOfoSent":
+		s.TCPDSACKOfoSent = value
+	case "TCPDSACKRecv":
+		s.TCPDSACKRecv = value
+	case "TCPDSACKOfoRecv":
+		s.TCPDSACKOfoRecv = value
+	case "TCPAbortOnData":
+		s.TCPAbortOnData = value
+	case "TCPAbortOnClose":
+		s.TCPAbortOnClose = value
+	case "TCPAbortOnMemory":
+		s.TCPAbortOnMemory = value
+	case "TCPAbortOnTimeout":
+		s.TCPAbortOnTimeout = value
+	case "TCPAbortOnLinger":
+		s.TCPAbortOnLinger = value
+	case "TCPAbortFailed":
+		s.TCPAbortFailed = value
+	case "TCPMemoryPressures":
+		s.TCPMemoryPressures = value
+	case "TCPMemoryPressuresChrono":
+		s.TCPMemoryPressuresChrono = value
+	case "TCPSACKDiscard":
+		s.TCPSACKDiscard = value
+	case "TCPDSACKIgnoredOld":
+		s.TCPDSACKIgnoredOld = value
+	case "TCPDSACKIgnoredNoUndo":
+		s.TCPDSACKIgnoredNoUndo = value
+	case "TCPSpuriousRTOs":
+		s.TCPSpuriousRTOs = value
+	case "TCPMD5NotFound":
+		s.TCPMD5NotFound = value
+	case "TCPMD5Unexpected":
+		s.TCPMD5Unexpected = value
+	case "TCPMD5Failure":
+		s.TCPMD5Failure = value
+	case "TCPSackShifted":
+		s.TCPSackShifted = value
+	case "TCPSackMerged":
+		s.TCPSackMerged = value
+	case "TCPSackShiftFallback":
+		s.TCPSackShiftFallback = value
+	case "TCPBacklogDrop":
+		s.TCPBacklogDrop = value
+	case "PFMemallocDrop":
+		s.PFMemallocDrop = value
+	case "TCPMinTTLDrop":
+		s.TCPMinTTLDrop = value
+	case "TCPDeferAcceptDrop":
+		s.TCPDeferAcceptDrop = value
+	case "IPReversePathFilter":
+		s.IPReversePathFilter = value
+	case "TCPTimeWaitOverflow":
+		s.TCPTimeWaitOverflow = value
+	case "TCPReqQFullDoCookies":
+		s.TCPReqQFullDoCookies = value
+	case "TCPReqQFullDrop":
+		s.TCPReqQFullDrop = value
+	case "TCPRetransFail":
+		s.TCPRetransFail = value
+	case "TCPRcvCoalesce":
+		s.TCPRcvCoalesce = value
+	case "TCPOFOQueue":
+		s.TCPOFOQueue = value
+	case "TCPOFODrop":
+		s.TCPOFODrop = value
+	case "TCPOFOMerge":
+		s.TCPOFOMerge = value
+	case "TCPChallengeACK":
+		s.TCPChallengeACK = value
+	case "TCPSYNChallenge":
+		s.TCPSYNChallenge = value
+	case "TCPFastOpenActive":
+		s.TCPFastOpenActive = value
+	case "TCPFastOpenActiveFail":
+		s.TCPFastOpenActiveFail = value
+	case "TCPFastOpenPassive":
+		s.TCPFastOpenPassive = value
+	case "TCPFastOpenPassiveFail":
+		s.TCPFastOpenPassiveFail = value
+	case "TCPFastOpenListenOverflow":
+		s.TCPFastOpenListenOverflow = value
+	case "TCPFastOpenCookieReqd":
+		s.TCPFastOpenCookieReqd = value
+	case "TCPFastOpenBlackhole":
+		s.TCPFastOpenBlackhole = value
+	case "TCPSpuriousRtxHostQueues":
+		s.TCPSpuriousRtxHostQueues = value
+	case "BusyPollRxPackets":
+		s.BusyPollRxPackets = value
+	case "TCPAutoCorking":
+		s.TCPAutoCorking = value
+	case "TCPFromZeroWindowAdv":
+		s.TCPFromZeroWindowAdv = value
+	case "TCPToZeroWindowAdv":
+		s.TCPToZeroWindowAdv = value
+	case "TCPWantZeroWindowAdv":
+		s.TCPWantZeroWindowAdv = value
+	case "TCPSynRetrans":
+		s.TCPSynRetrans = value
+	case "TCPOrigDataSent":
+		s.TCPOrigDataSent = value
+	case "TCPKeepAlive":
+		s.TCPKeepAlive = value
+	case "TCPDelivered":
+		s.TCPDelivered = value
+	case "TCPDeliveredCE":
+		s.TCPDeliveredCE = value
+	default:
+		return false
+	}
+	return true
+}
+
+// Set the field named key, reporting whether there is one.
+func (s *IpExt) set(key string, value uint64) bool {
+	switch key {
+	case "InNoRoutes":
+		s.InNoRoutes = value
+	case "InTruncatedPkts":
+		s.InTruncatedPkts = value
+	case "InMcastPkts":
+		s.InMcastPkts = value
+	case "OutMcastPkts":
+		s.OutMcastPkts = value
+	case "InBcastPkts":
+		s.InBcastPkts = value
+	case "OutBcastPkts":
+		s.OutBcastPkts = value
+	case "InOctets":
+		s.InOctets = value
+	case "OutOctets":
+		s.OutOctets = value
+	case "InMcastOctets":
+		s.InMcastOctets = value
+	case "OutMcastOctets":
+		s.OutMcastOctets = value
+	case "InBcastOctets":
+		s.InBcastOctets = value
+	case "OutBcastOctets":
+		s.OutBcastOctets = value
+	case "InCsumErrors":
+		s.InCsumErrors = value
+	case "InNoECTPkts":
+		s.InNoECTPkts = value
+	case "InECT1Pkts":
+		s.InECT1Pkts = value
+	case "InECT0Pkts":
+		s.InECT0Pkts = value
+	case "InCEPkts":
+		s.InCEPkts = value
+	case "ReasmOverlaps":
+		s.ReasmOverlaps = value
+	default:
+		return false
+	}
+	return true
+}
+
+// Get values of /proc/net/netstat.
+// Example:
+//     myNetstat, err := netstat.Get()
+//     x := myNetstat.TcpExt.ListenOverflows
+func Get() (Netstat, error) {
+	return GetFrom(procfs.Default)
+}
+
+// Get values of net/netstat from the procfs tree fsys.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     myNetstat, err := netstat.GetFrom(myFS)
+func GetFrom(fsys fs.FS) (Netstat, error) {
+
+	result := Netstat{
+		Extra: make(map[string]map[string]uint64),
+	}
+
+	// Keep what could be parsed along with a ParseError of a strict tree.
+
+	contents, err := GetAsMapFrom(fsys)
+
+	for section, fields := range contents {
+		for key, value := range fields {
+			known := false
+			switch section {
+			case "TcpExt":
+				known = result.TcpExt.set(key, value)
+			case "IpExt":
+				known = result.IpExt.set(key, value)
+			}
+			if known {
+				continue
+			}
+			if result.Extra[section] == nil {
+				result.Extra[section] = make(map[string]uint64)
+			}
+			result.Extra[section][key] = value
+		}
+	}
+	return result, err
+}
+
+func GetAsJson() ([]byte, error) {
+	return GetAsJsonFrom(procfs.Default)
+}
+
+func GetAsJsonFrom(fsys fs.FS) ([]byte, error) {
+	content, err := GetFrom(fsys)
+	if err != nil {
+		return []byte{}, err
+	}
+	return json.Marshal(content)
+}
+
+// Get values of /proc/net/netstat as a map of maps of uint64, including
+// counters not known to this package.
+// Example:
+//     myNetstat := netstat.GetAsMap()
+//     x := myNetstat["TcpExt"]["TCPTimeouts"]
+func GetAsMap() (map[string]map[string]uint64, error) {
+	return GetAsMapFrom(procfs.Default)
+}
+
+// Get values of net/netstat from the procfs tree fsys as a map of maps of
+// uint64.
+func GetAsMapFrom(fsys fs.FS) (map[string]map[string]uint64, error) {
+
+	result := make(map[string]map[string]uint64)
+
+	// Transform string data to uint64 and put in result.
+
+	check := procfs.NewChecker(fsys, filename)
+	err := procfs.ReadTable(fsys, filename, check, func(section string, key string, split string) {
+		if result[section] == nil {
+			result[section] = make(map[string]uint64)
+		}
+		value, err := strconv.ParseUint(split, 10, 64)
+		if err != nil {
+			check.Report(key, split, err)
+			return
+		}
+		result[section][key] = value
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, check.Err()
+}
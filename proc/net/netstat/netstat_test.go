@@ -0,0 +1,83 @@
+package netstat
+
+import (
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"io/fs"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// The trees under testdata/, read from an fs.FS and, through PROC_ROOT, from
+// the default procfs tree.
+func TestGolden(t *testing.T) {
+	for _, tree := range golden.Trees(t) {
+		t.Run(tree.Name, func(t *testing.T) {
+			content, err := GetFrom(tree.FS)
+			golden.Check(t, tree, "net_netstat", content, err)
+		})
+		t.Run(tree.Name+"/PROC_ROOT", func(t *testing.T) {
+			tree.SetRoot(t)
+			content, err := Get()
+			golden.Check(t, tree, "net_netstat", content, err)
+		})
+	}
+}
+
+// Single values, including those of a section that Netstat has no struct
+// for, which go to Extra.
+func TestGetFrom(t *testing.T) {
+	netstat, err := GetFrom(golden.Find(t, "linux-6.18-container-x86_64").FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if netstat.TcpExt.TW != 19 || netstat.IpExt.InOctets != 58241481 || netstat.IpExt.OutOctets != 58242328 {
+		t.Errorf("got TcpExt TW %d, IpExt InOctets %d, OutOctets %d", netstat.TcpExt.TW, netstat.IpExt.InOctets, netstat.IpExt.OutOctets)
+	}
+	if _, found := netstat.Extra["MPTcpExt"]["MPCapableSYNRX"]; !found {
+		t.Errorf("Extra: got %v, want MPTcpExt MPCapableSYNRX", netstat.Extra)
+	}
+}
+
+// Malformed lines give a ParseError in strict mode and a warning in lenient
+// mode; the other values are still read.
+func TestGetFromMalformed(t *testing.T) {
+	tests := []golden.Malformed{
+		{
+			Name:     "value",
+			Data:     "TcpExt: TW SyncookiesSent\nTcpExt: 1x 2\nMPTcpExt: MPCapableSYNRX\nMPTcpExt: 3\n",
+			Expected: Netstat{TcpExt: TcpExt{SyncookiesSent: 2}, Extra: map[string]map[string]uint64{"MPTcpExt": {"MPCapableSYNRX": 3}}},
+			Wants:    []procfs.ParseError{{File: "net/netstat", Line: 2, Field: "TW", Text: "1x", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "out of range",
+			Data:     "IpExt: InOctets OutOctets\nIpExt: 18446744073709551616 7\n",
+			Expected: Netstat{IpExt: IpExt{OutOctets: 7}, Extra: map[string]map[string]uint64{}},
+			Wants:    []procfs.ParseError{{File: "net/netstat", Line: 2, Field: "InOctets", Text: "18446744073709551616", Err: strconv.ErrRange}},
+		},
+		{
+			Name:     "values without header",
+			Data:     "IpExt: InOctets OutOctets\nIpExt: 5\n",
+			Expected: Netstat{IpExt: IpExt{InOctets: 5}, Extra: map[string]map[string]uint64{}},
+			Wants:    []procfs.ParseError{{File: "net/netstat", Line: 2, Text: "IpExt: 5", Err: procfs.ErrHeaderMismatch}},
+		},
+	}
+	for _, test := range tests {
+		golden.CheckMalformed(t, "net/netstat", test, func(fsys fs.FS) (interface{}, error) { return GetFrom(fsys) })
+	}
+}
+
+// Parse any net/netstat without panicking, into a result or a ParseError.
+func FuzzGetFrom(f *testing.F) {
+	golden.Seed(f, "net/netstat")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, fsys := range golden.Modes(fstest.MapFS{"net/netstat": {Data: data}}) {
+			_, err := GetFrom(fsys)
+			golden.CheckError(t, err)
+			_, err = GetAsMapFrom(fsys)
+			golden.CheckError(t, err)
+		}
+	})
+}
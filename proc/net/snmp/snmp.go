@@ -1,82 +1,406 @@
 package snmp
 
 import (
-	"bufio"
 	"encoding/json"
-	"os"
+	"io/fs"
 	"strconv"
-	"strings"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
 )
 
+// References:
+// - http://man7.org/linux/man-pages/man5/proc.5.html  "/proc/net/snmp"
+// - https://tools.ietf.org/html/rfc1213  "MIB-II"
+type Snmp struct {
+	Ip      Ip      `json:"Ip"`
+	Icmp    Icmp    `json:"Icmp"`
+	IcmpMsg IcmpMsg `json:"IcmpMsg"`
+	Tcp     Tcp     `json:"Tcp"`
+	Udp     Udp     `json:"Udp"`
+	UdpLite UdpLite `json:"UdpLite"`
+}
+
+type Ip struct {
+	Forwarding      uint64 `json:"Forwarding"`
+	DefaultTTL      uint64 `json:"DefaultTTL"`
+	InReceives      uint64 `json:"InReceives"`
+	InHdrErrors     uint64 `json:"InHdrErrors"`
+	InAddrErrors    uint64 `json:"InAddrErrors"`
+	ForwDatagrams   uint64 `json:"ForwDatagrams"`
+	InUnknownProtos uint64 `json:"InUnknownProtos"`
+	InDiscards      uint64 `json:"InDiscards"`
+	InDelivers      uint64 `json:"InDelivers"`
+	OutRequests     uint64 `json:"OutRequests"`
+	OutDiscards     uint64 `json:"OutDiscards"`
+	OutNoRoutes     uint64 `json:"OutNoRoutes"`
+	ReasmTimeout    uint64 `json:"ReasmTimeout"`
+	ReasmReqds      uint64 `json:"ReasmReqds"`
+	ReasmOKs        uint64 `json:"ReasmOKs"`
+	ReasmFails      uint64 `json:"ReasmFails"`
+	FragOKs         uint64 `json:"FragOKs"`
+	FragFails       uint64 `json:"FragFails"`
+	FragCreates     uint64 `json:"FragCreates"`
+	OutTransmits    uint64 `json:"OutTransmits"`
+}
+
+type Icmp struct {
+	InMsgs             uint64 `json:"InMsgs"`
+	InErrors           uint64 `json:"InErrors"`
+	InCsumErrors       uint64 `json:"InCsumErrors"`
+	InDestUnreachs     uint64 `json:"InDestUnreachs"`
+	InTimeExcds        uint64 `json:"InTimeExcds"`
+	InParmProbs        uint64 `json:"InParmProbs"`
+	InSrcQuenchs       uint64 `json:"InSrcQuenchs"`
+	InRedirects        uint64 `json:"InRedirects"`
+	InEchos            uint64 `json:"InEchos"`
+	InEchoReps         uint64 `json:"InEchoReps"`
+	InTimestamps       uint64 `json:"InTimestamps"`
+	InTimestampReps    uint64 `json:"InTimestampReps"`
+	InAddrMasks        uint64 `json:"InAddrMasks"`
+	InAddrMaskReps     uint64 `json:"InAddrMaskReps"`
+	OutMsgs            uint64 `json:"OutMsgs"`
+	OutErrors          uint64 `json:"OutErrors"`
+	OutRateLimitGlobal uint64 `json:"OutRateLimitGlobal"`
+	OutRateLimitHost   uint64 `json:"OutRateLimitHost"`
+	OutDestUnreachs    uint64 `json:"OutDestUnreachs"`
+	OutTimeExcds       uint64 `json:"OutTimeExcds"`
+	OutParmProbs       uint64 `json:"OutParmProbs"`
+	OutSrcQuenchs      uint64 `json:"OutSrcQuenchs"`
+	OutRedirects       uint64 `json:"OutRedirects"`
+	OutEchos           uint64 `json:"OutEchos"`
+	OutEchoReps        uint64 `json:"OutEchoReps"`
+	OutTimestamps      uint64 `json:"OutTimestamps"`
+	OutTimestampReps   uint64 `json:"OutTimestampReps"`
+	OutAddrMasks       uint64 `json:"OutAddrMasks"`
+	OutAddrMaskReps    uint64 `json:"OutAddrMaskReps"`
+}
+
+// ICMP messages by direction and type, e.g. "InType3" and "OutType8".  Only
+// types that have been seen are listed.
+type IcmpMsg map[string]uint64
+
+// MaxConn is -1 when the maximum number of connections is dynamic.
+type Tcp struct {
+	RtoAlgorithm uint64 `json:"RtoAlgorithm"`
+	RtoMin       uint64 `json:"RtoMin"`
+	RtoMax       uint64 `json:"RtoMax"`
+	MaxConn      int64  `json:"MaxConn"`
+	ActiveOpens  uint64 `json:"ActiveOpens"`
+	PassiveOpens uint64 `json:"PassiveOpens"`
+	AttemptFails uint64 `json:"AttemptFails"`
+	EstabResets  uint64 `json:"EstabResets"`
+	CurrEstab    uint64 `json:"CurrEstab"`
+	InSegs       uint64 `json:"InSegs"`
+	OutSegs      uint64 `json:"OutSegs"`
+	RetransSegs  uint64 `json:"RetransSegs"`
+	InErrs       uint64 `json:"InErrs"`
+	OutRsts      uint64 `json:"OutRsts"`
+	InCsumErrors uint64 `json:"InCsumErrors"`
+}
+
+type Udp struct {
+	InDatagrams  uint64 `json:"InDatagrams"`
+	NoPorts      uint64 `json:"NoPorts"`
+	InErrors     uint64 `json:"InErrors"`
+	OutDatagrams uint64 `json:"OutDatagrams"`
+	RcvbufErrors uint64 `json:"RcvbufErrors"`
+	SndbufErrors uint64 `json:"SndbufErrors"`
+	InCsumErrors uint64 `json:"InCsumErrors"`
+	IgnoredMulti uint64 `json:"IgnoredMulti"`
+	MemErrors    uint64 `json:"MemErrors"`
+}
+
+// UDP-Lite has the same counters as UDP.
+type UdpLite Udp
+
+// Path of the file relative to the procfs root.
+const filename = "net/snmp"
+
 // Allow filename to be specified by OS Environment variable: PROC_NET_SNMP
 func GetFilename() string {
-	result := os.Getenv("PROC_NET_SNMP")
-	if result == "" {
-		result = "/proc/net/snmp"
+	return procfs.Filename(filename)
+}
+
+func (s *Ip) set(check *procfs.Checker, key string, value string) {
+	switch key {
+	case "Forwarding":
+		s.Forwarding = check.Uint64(key, value, 10)
+	case "DefaultTTL":
+		s.DefaultTTL = check.Uint64(key, value, 10)
+	case "InReceives":
+		s.InReceives = check.Uint64(key, value, 10)
+	case "InHdrErrors":
+		s.InHdrErrors = check.Uint64(key, value, 10)
+	case "InAddrErrors":
+		s.InAddrErrors = check.Uint64(key, value, 10)
+	case "ForwDatagrams":
+		s.ForwDatagrams = check.Uint64(key, value, 10)
+	case "InUnknownProtos":
+		s.InUnknownProtos = check.Uint64(key, value, 10)
+	case "InDiscards":
+		s.InDiscards = check.Uint64(key, value, 10)
+	case "InDelivers":
+		s.InDelivers = check.Uint64(key, value, 10)
+	case "OutRequests":
+		s.OutRequests = check.Uint64(key, value, 10)
+	case "OutDiscards":
+		s.OutDiscards = check.Uint64(key, value, 10)
+	case "OutNoRoutes":
+		s.OutNoRoutes = check.Uint64(key, value, 10)
+	case "ReasmTimeout":
+		s.ReasmTimeout = check.Uint64(key, value, 10)
+	case "ReasmReqds":
+		s.ReasmReqds = check.Uint64(key, value, 10)
+	case "ReasmOKs":
+		s.ReasmOKs = check.Uint64(key, value, 10)
+	case "ReasmFails":
+		s.ReasmFails = check.Uint64(key, value, 10)
+	case "FragOKs":
+		s.FragOKs = check.Uint64(key, value, 10)
+	case "FragFails":
+		s.FragFails = check.Uint64(key, value, 10)
+	case "FragCreates":
+		s.FragCreates = check.Uint64(key, value, 10)
+	case "OutTransmits":
+		s.OutTransmits = check.Uint64(key, value, 10)
 	}
-	return result
 }
 
-func GetAsJson() ([]byte, error) {
-	content, err := GetAsMap()
-	if err != nil {
-		return []byte{}, err
+func (s *Icmp) set(check *procfs.Checker, key string, value string) {
+	switch key {
+	case "InMsgs":
+		s.InMsgs = check.Uint64(key, value, 10)
+	case "InErrors":
+		s.InErrors = check.Uint64(key, value, 10)
+	case "InCsumErrors":
+		s.InCsumErrors = check.Uint64(key, value, 10)
+	case "InDestUnreachs":
+		s.InDestUnreachs = check.Uint64(key, value, 10)
+	case "InTimeExcds":
+		s.InTimeExcds = check.Uint64(key, value, 10)
+	case "InParmProbs":
+		s.InParmProbs = check.Uint64(key, value, 10)
+	case "InSrcQuenchs":
+		s.InSrcQuenchs = check.Uint64(key, value, 10)
+	case "InRedirects":
+		s.InRedirects = check.Uint64(key, value, 10)
+	case "InEchos":
+		s.InEchos = check.Uint64(key, value, 10)
+	case "InEchoReps":
+		s.InEchoReps = check.Uint64(key, value, 10)
+	case "InTimestamps":
+		s.InTimestamps = check.Uint64(key, value, 10)
+	case "InTimestampReps":
+		s.InTimestampReps = check.Uint64(key, value, 10)
+	case "InAddrMasks":
+		s.InAddrMasks = check.Uint64(key, value, 10)
+	case "InAddrMaskReps":
+		s.InAddrMaskReps = check.Uint64(key, value, 10)
+	case "OutMsgs":
+		s.OutMsgs = check.Uint64(key, value, 10)
+	case "OutErrors":
+		s.OutErrors = check.Uint64(key, value, 10)
+	case "OutRateLimitGlobal":
+		s.OutRateLimitGlobal = check.Uint64(key, value, 10)
+	case "OutRateLimitHost":
+		s.OutRateLimitHost = check.Uint64(key, value, 10)
+	case "OutDestUnreachs":
+		s.OutDestUnreachs = check.Uint64(key, value, 10)
+	case "OutTimeExcds":
+		s.OutTimeExcds = check.Uint64(key, value, 10)
+	case "OutParmProbs":
+		s.OutParmProbs = check.Uint64(key, value, 10)
+	case "OutSrcQuenchs":
+		s.OutSrcQuenchs = check.Uint64(key, value, 10)
+	case "OutRedirects":
+		s.OutRedirects = check.Uint64(key, value, 10)
+	case "OutEchos":
+		s.OutEchos = check.Uint64(key, value, 10)
+	case "OutEchoReps":
+		s.OutEchoReps = check.Uint64(key, value, 10)
+	case "OutTimestamps":
+		s.OutTimestamps = check.Uint64(key, value, 10)
+	case "OutTimestampReps":
+		s.OutTimestampReps = check.Uint64(key, value, 10)
+	case "OutAddrMasks":
+		s.OutAddrMasks = check.Uint64(key, value, 10)
+	case "OutAddrMaskReps":
+		s.OutAddrMaskReps = check.Uint64(key, value, 10)
 	}
-	result, _ := json.Marshal(content)
-	return result, nil
 }
 
-// Get values of /proc/net/snmp as a map of maps of uint64.
+func (s *Tcp) set(check *procfs.Checker, key string, value string) {
+	switch key {
+	case "RtoAlgorithm":
+		s.RtoAlgorithm = check.Uint64(key, value, 10)
+	case "RtoMin":
+		s.RtoMin = check.Uint64(key, value, 10)
+	case "RtoMax":
+		s.RtoMax = check.Uint64(key, value, 10)
+	case "MaxConn":
+		s.MaxConn = check.Int64(key, value, 10)
+	case "ActiveOpens":
+		s.ActiveOpens = check.Uint64(key, value, 10)
+	case "PassiveOpens":
+		s.PassiveOpens = check.Uint64(key, value, 10)
+	case "AttemptFails":
+		s.AttemptFails = check.Uint64(key, value, 10)
+	case "EstabResets":
+		s.EstabResets = check.Uint64(key, value, 10)
+	case "CurrEstab":
+		s.CurrEstab = check.Uint64(key, value, 10)
+	case "InSegs":
+		s.InSegs = check.Uint64(key, value, 10)
+	case "OutSegs":
+		s.OutSegs = check.Uint64(key, value, 10)
+	case "RetransSegs":
+		s.RetransSegs = check.Uint64(key, value, 10)
+	case "InErrs":
+		s.InErrs = check.Uint64(key, value, 10)
+	case "OutRsts":
+		s.OutRsts = check.Uint64(key, value, 10)
+	case "InCsumErrors":
+		s.InCsumErrors = check.Uint64(key, value, 10)
+	}
+}
+
+func (s *Udp) set(check *procfs.Checker, key string, value string) {
+	switch key {
+	case "InDatagrams":
+		s.InDatagrams = check.Uint64(key, value, 10)
+	case "NoPorts":
+		s.NoPorts = check.Uint64(key, value, 10)
+	case "InErrors":
+		s.InErrors = check.Uint64(key, value, 10)
+	case "OutDatagrams":
+		s.OutDatagrams = check.Uint64(key, value, 10)
+	case "RcvbufErrors":
+		s.RcvbufErrors = check.Uint64(key, value, 10)
+	case "SndbufErrors":
+		s.SndbufErrors = check.Uint64(key, value, 10)
+	case "InCsumErrors":
+		s.InCsumErrors = check.Uint64(key, value, 10)
+	case "IgnoredMulti":
+		s.IgnoredMulti = check.Uint64(key, value, 10)
+	case "MemErrors":
+		s.MemErrors = check.Uint64(key, value, 10)
+	}
+}
+
+// Get values of /proc/net/snmp.
 // Example:
-//     mySnmp := snmp.Get()
-//     x := mySnmp["Ip"]["InHdrErrors"]
-func GetAsMap() (map[string]map[string]uint64, error) {
+//     mySnmp, err := snmp.Get()
+//     x := mySnmp.Udp.OutDatagrams
+func Get() (Snmp, error) {
+	return GetFrom(procfs.Default)
+}
 
-	result := make(map[string]map[string]uint64)
+// Get values of net/snmp from the procfs tree fsys.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     mySnmp, err := snmp.GetFrom(myFS)
+func GetFrom(fsys fs.FS) (Snmp, error) {
 
-	// Open the file.
+	result := Snmp{
+		IcmpMsg: IcmpMsg{},
+	}
 
-	fileName := GetFilename()
-	file, err := os.Open(fileName)
+	check := procfs.NewChecker(fsys, filename)
+	err := procfs.ReadTable(fsys, filename, check, func(protocol string, key string, value string) {
+		switch protocol {
+		case "Ip":
+			result.Ip.set(check, key, value)
+		case "Icmp":
+			result.Icmp.set(check, key, value)
+		case "IcmpMsg":
+			result.IcmpMsg[key] = check.Uint64(key, value, 10)
+		case "Tcp":
+			result.Tcp.set(check, key, value)
+		case "Udp":
+			result.Udp.set(check, key, value)
+		case "UdpLite":
+			(*Udp)(&result.UdpLite).set(check, key, value)
+		}
+	})
 	if err != nil {
 		return result, err
 	}
+	return result, check.Err()
+}
 
-	// Oscillate between header and non-header lines in file.
+func GetAsJson() ([]byte, error) {
+	return GetAsJsonFrom(procfs.Default)
+}
 
-	header := true
-	headerSplits := []string{}
+func GetAsJsonFrom(fsys fs.FS) ([]byte, error) {
+	content, err := GetFrom(fsys)
+	if err != nil {
+		return []byte{}, err
+	}
+	return json.Marshal(content)
+}
 
-	// Read the file.
+// Get values of /proc/net/snmp as JSON with the keys of struct fields in the case
+// keys.  Keys of maps are left as they are.
+// Example:
+//     myJson, err := snmp.GetAsJsonKeys(proc.SnakeCase) // {"ip":{"in_hdr_errors":...}}
+func GetAsJsonKeys(keys procfs.KeyCase) ([]byte, error) {
+	return GetAsJsonKeysFrom(procfs.Default, keys)
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		inputLine := scanner.Text()
-		splits := strings.Fields(inputLine)
+func GetAsJsonKeysFrom(fsys fs.FS, keys procfs.KeyCase) ([]byte, error) {
+	content, err := GetFrom(fsys)
+	if err != nil {
+		return []byte{}, err
+	}
+	return procfs.MarshalJSON(content, keys)
+}
 
-		// Pull out the first-level key for the map-map structure.
+// Fields of /proc/net/snmp that are signed, by protocol.
+var signedFields = map[string]map[string]bool{
+	"Tcp": {"MaxConn": true},
+}
 
-		keySplits := strings.Split(splits[0], ":")
-		key := keySplits[0]
+// Get values of /proc/net/snmp as a map of maps.  Values are uint64, except
+// for signed fields, such as Tcp MaxConn, which are int64.
+// Example:
+//     mySnmp := snmp.GetAsMap()
+//     x := mySnmp["Ip"]["InHdrErrors"].(uint64)
+func GetAsMap() (map[string]map[string]interface{}, error) {
+	return GetAsMapFrom(procfs.Default)
+}
 
-		// Handle header and data lines in the file.
+// Get values of net/snmp from the procfs tree fsys as a map of maps.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     mySnmp, err := snmp.GetAsMapFrom(myFS)
+func GetAsMapFrom(fsys fs.FS) (map[string]map[string]interface{}, error) {
 
-		if header {
-			headerSplits = splits
-			result[key] = make(map[string]uint64)
-		} else {
+	result := make(map[string]map[string]interface{})
 
-			// Transform string data to uint64 and put in result.
+	// Transform string data to uint64, or int64 for signed fields, and put
+	// in result.
 
-			for index, split := range splits {
-				value, err := strconv.ParseUint(split, 10, 64)
-				if err != nil {
-					continue
-				}
-				result[key][headerSplits[index]] = value
-			}
+	check := procfs.NewChecker(fsys, filename)
+	err := procfs.ReadTable(fsys, filename, check, func(protocol string, key string, split string) {
+		if result[protocol] == nil {
+			result[protocol] = make(map[string]interface{})
+		}
+		var value interface{}
+		var err error
+		if signedFields[protocol][key] {
+			value, err = strconv.ParseInt(split, 10, 64)
+		} else {
+			value, err = strconv.ParseUint(split, 10, 64)
 		}
-		header = !header // Oscillate between header and non-header lines in file.
+		if err != nil {
+			check.Report(key, split, err)
+			return
+		}
+		result[protocol][key] = value
+	})
+	if err != nil {
+		return result, err
 	}
-	return result, nil
+	return result, check.Err()
 }
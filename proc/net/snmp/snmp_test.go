@@ -0,0 +1,134 @@
+package snmp
+
+import (
+	"errors"
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// The trees under testdata/, read from an fs.FS and, through PROC_ROOT, from
+// the default procfs tree.
+func TestGolden(t *testing.T) {
+	for _, tree := range golden.Trees(t) {
+		t.Run(tree.Name, func(t *testing.T) {
+			content, err := GetFrom(tree.FS)
+			golden.Check(t, tree, "net_snmp", content, err)
+		})
+		t.Run(tree.Name+"/PROC_ROOT", func(t *testing.T) {
+			tree.SetRoot(t)
+			content, err := Get()
+			golden.Check(t, tree, "net_snmp", content, err)
+		})
+	}
+}
+
+// Single values, including the signed Tcp MaxConn and the columns that only
+// newer kernels write.
+func TestGetFrom(t *testing.T) {
+	snmp, err := GetFrom(golden.Find(t, "linux-6.18-container-x86_64").FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Tcp{
+		RtoAlgorithm: 1, RtoMin: 200, RtoMax: 120000, MaxConn: -1,
+		ActiveOpens: 31, PassiveOpens: 26, EstabResets: 19, CurrEstab: 2,
+		InSegs: 6620, OutSegs: 6620, OutRsts: 6,
+	}
+	if snmp.Tcp != expected {
+		t.Errorf("Tcp: got %+v, want %+v", snmp.Tcp, expected)
+	}
+
+	old, err := GetAsMapFrom(golden.Find(t, "synthetic-linux-2.6.32-centos6-x86_64").FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, found := old["Udp"]["InCsumErrors"]; found {
+		t.Errorf("2.6.32: Udp InCsumErrors: got %v, want no value", value)
+	}
+	newer, err := GetAsMapFrom(golden.Find(t, "synthetic-linux-4.15-ubuntu18.04-x86_64").FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newer["Udp"]["IgnoredMulti"] != uint64(2011) {
+		t.Errorf("4.15: Udp IgnoredMulti: got %#v, want 2011", newer["Udp"]["IgnoredMulti"])
+	}
+}
+
+// The map holds signed fields as int64 and the other fields as uint64, and
+// leaves out values that cannot be parsed.
+func TestGetAsMapFrom(t *testing.T) {
+	snmp, err := GetAsMapFrom(golden.Find(t, "linux-6.18-container-x86_64").FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snmp["Tcp"]["MaxConn"] != int64(-1) || snmp["Tcp"]["RtoMin"] != uint64(200) {
+		t.Errorf("Tcp: got MaxConn %#v, RtoMin %#v, want int64(-1), uint64(200)", snmp["Tcp"]["MaxConn"], snmp["Tcp"]["RtoMin"])
+	}
+
+	tree := fstest.MapFS{"net/snmp": {Data: []byte("Tcp: MaxConn ActiveOpens InSegs\nTcp: 200 -1 7\n")}}
+	snmp, err = GetAsMapFrom(procfs.Strict(tree))
+	var parseError *procfs.ParseError
+	if !errors.As(err, &parseError) || parseError.Field != "ActiveOpens" {
+		t.Errorf("got %v, want a ParseError of ActiveOpens", err)
+	}
+	expected := map[string]map[string]interface{}{"Tcp": {"MaxConn": int64(200), "InSegs": uint64(7)}}
+	if !reflect.DeepEqual(snmp, expected) {
+		t.Errorf("got %#v, want %#v", snmp, expected)
+	}
+}
+
+// Malformed lines give a ParseError in strict mode and a warning in lenient
+// mode; the other values are still read.
+func TestGetFromMalformed(t *testing.T) {
+	tests := []golden.Malformed{
+		{
+			Name:     "value",
+			Data:     "Ip: Forwarding DefaultTTL InReceives\nIp: 1 6x 3\n",
+			Expected: Snmp{IcmpMsg: IcmpMsg{}, Ip: Ip{Forwarding: 1, InReceives: 3}},
+			Wants:    []procfs.ParseError{{File: "net/snmp", Line: 2, Field: "DefaultTTL", Text: "6x", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "negative counter",
+			Data:     "Udp: InDatagrams NoPorts\nUdp: -1 2\n",
+			Expected: Snmp{IcmpMsg: IcmpMsg{}, Udp: Udp{NoPorts: 2}},
+			Wants:    []procfs.ParseError{{File: "net/snmp", Line: 2, Field: "InDatagrams", Text: "-1", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "values without header",
+			Data:     "Tcp: RtoAlgorithm RtoMin RtoMax\nTcp: 1 200\nUdp: InDatagrams\nUdp: 5\n",
+			Expected: Snmp{IcmpMsg: IcmpMsg{}, Tcp: Tcp{RtoAlgorithm: 1, RtoMin: 200}, Udp: Udp{InDatagrams: 5}},
+			Wants:    []procfs.ParseError{{File: "net/snmp", Line: 2, Text: "Tcp: 1 200", Err: procfs.ErrHeaderMismatch}},
+		},
+		{
+			Name:     "two errors",
+			Data:     "Tcp: MaxConn ActiveOpens\nTcp: x -1\n",
+			Expected: Snmp{IcmpMsg: IcmpMsg{}},
+			Wants: []procfs.ParseError{
+				{File: "net/snmp", Line: 2, Field: "MaxConn", Text: "x", Err: strconv.ErrSyntax},
+				{File: "net/snmp", Line: 2, Field: "ActiveOpens", Text: "-1", Err: strconv.ErrSyntax},
+			},
+		},
+	}
+	for _, test := range tests {
+		golden.CheckMalformed(t, "net/snmp", test, func(fsys fs.FS) (interface{}, error) { return GetFrom(fsys) })
+	}
+}
+
+// Parse any net/snmp without panicking, into a result or a ParseError.
+func FuzzGetFrom(f *testing.F) {
+	golden.Seed(f, "net/snmp")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, fsys := range golden.Modes(fstest.MapFS{"net/snmp": {Data: data}}) {
+			_, err := GetFrom(fsys)
+			golden.CheckError(t, err)
+			_, err = GetAsMapFrom(fsys)
+			golden.CheckError(t, err)
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package tcp
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// State of a socket.  UDP and raw sockets use the same codes.
+// References:
+// - include/net/tcp_states.h
+type State uint8
+
+const (
+	Established State = 0x01
+	SynSent     State = 0x02
+	SynRecv     State = 0x03
+	FinWait1    State = 0x04
+	FinWait2    State = 0x05
+	TimeWait    State = 0x06
+	Close       State = 0x07
+	CloseWait   State = 0x08
+	LastAck     State = 0x09
+	Listen      State = 0x0A
+	Closing     State = 0x0B
+	NewSynRecv  State = 0x0C
+)
+
+var stateNames = map[State]string{
+	Established: "ESTABLISHED",
+	SynSent:     "SYN_SENT",
+	SynRecv:     "SYN_RECV",
+	FinWait1:    "FIN_WAIT1",
+	FinWait2:    "FIN_WAIT2",
+	TimeWait:    "TIME_WAIT",
+	Close:       "CLOSE",
+	CloseWait:   "CLOSE_WAIT",
+	LastAck:     "LAST_ACK",
+	Listen:      "LISTEN",
+	Closing:     "CLOSING",
+	NewSynRecv:  "NEW_SYN_RECV",
+}
+
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return "UNKNOWN_" + strconv.Itoa(int(s))
+}
+
+// States are written to JSON by name, e.g. "LISTEN".
+func (s State) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// A line of /proc/net/tcp, tcp6, udp, udp6, raw or raw6.  Inode identifies the
+// socket in the "socket:[inode]" links of /proc/[pid]/fd.
+// References:
+// - http://man7.org/linux/man-pages/man5/proc.5.html  "/proc/net/tcp"
+// - https://www.kernel.org/doc/Documentation/networking/proc_net_tcp.txt
+type Socket struct {
+	Sl            int            `json:"sl"`
+	Local_address netip.AddrPort `json:"local_address"`
+	Rem_address   netip.AddrPort `json:"rem_address"`
+	St            State          `json:"st"`
+	Tx_queue      uint64         `json:"tx_queue"`
+	Rx_queue      uint64         `json:"rx_queue"`
+	Tr            int            `json:"tr"`
+	Tm_when       uint64         `json:"tm_when"`
+	Retrnsmt      uint64         `json:"retrnsmt"`
+	Uid           uint32         `json:"uid"`
+	Timeout       uint64         `json:"timeout"`
+	Inode         uint64         `json:"inode"`
+}
+
+// Paths of the files in the format of /proc/net/tcp, relative to the procfs
+// root.
+const (
+	Tcp  = "net/tcp"
+	Tcp6 = "net/tcp6"
+	Udp  = "net/udp"
+	Udp6 = "net/udp6"
+	Raw  = "net/raw"
+	Raw6 = "net/raw6"
+)
+
+// Allow the file name, e.g. Udp6, to be specified by OS Environment variable:
+// PROC_NET_TCP, PROC_NET_TCP6, PROC_NET_UDP, PROC_NET_UDP6, PROC_NET_RAW or
+// PROC_NET_RAW6.
+func GetFilename(name string) string {
+	return procfs.Filename(name)
+}
+
+var errInvalidAddress = errors.New("invalid address")
+
+// Decode an address such as "0100007F:1F90".  The kernel writes the address
+// as 32-bit words in host byte order and the port in network byte order.
+func asAddrPort(value string) (netip.AddrPort, bool) {
+	splits := strings.Split(value, ":")
+	if len(splits) != 2 {
+		return netip.AddrPort{}, false
+	}
+	port, err := strconv.ParseUint(splits[1], 16, 16)
+	if err != nil {
+		return netip.AddrPort{}, false
+	}
+	words, err := hex.DecodeString(splits[0])
+	if err != nil || (len(words) != 4 && len(words) != 16) {
+		return netip.AddrPort{}, false
+	}
+	for index := 0; index < len(words); index += 4 {
+		binary.NativeEndian.PutUint32(words[index:], binary.BigEndian.Uint32(words[index:]))
+	}
+	addr, _ := netip.AddrFromSlice(words)
+	return netip.AddrPortFrom(addr, uint16(port)), true
+}
+
+// Split a pair of hex values such as "00000000:00000000".
+func asHexPair(check *procfs.Checker, field string, value string) (uint64, uint64) {
+	first, second, found := strings.Cut(value, ":")
+	if !found {
+		check.Report(field, value, errors.New("missing \":\""))
+		return 0, 0
+	}
+	return check.Uint64(field, first, 16), check.Uint64(field, second, 16)
+}
+
+// Get values of /proc/net/tcp, or of another file in its format, e.g. Udp6.
+// Example:
+//     mySockets, err := tcp.Get(tcp.Tcp)
+//     x := mySockets[0].Local_address.Port()
+func Get(name string) ([]Socket, error) {
+	return GetFrom(procfs.Default, name)
+}
+
+// Get values of the file name, e.g. Udp6, from the procfs tree fsys.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     mySockets, err := tcp.GetFrom(myFS, tcp.Udp)
+func GetFrom(fsys fs.FS, name string) ([]Socket, error) {
+
+	result := []Socket{}
+
+	// Read the file in a single read.
+
+	data, err := procfs.ReadFile(fsys, name)
+	if err != nil {
+		return result, err
+	}
+
+	// Parse the file.
+
+	check := procfs.NewChecker(fsys, name)
+	scanner := procfs.NewScanner(data)
+	scanner.Scan() // Skip table header.
+	check.NextLine()
+	for scanner.Scan() {
+		check.NextLine()
+		inputLine := scanner.Text()
+		splits := strings.Fields(inputLine)
+		if len(splits) == 0 || !check.Fields(inputLine, splits, 10) {
+			continue
+		}
+
+		local, ok := asAddrPort(splits[1])
+		if !ok {
+			check.Report("local_address", splits[1], errInvalidAddress)
+			continue
+		}
+		remote, ok := asAddrPort(splits[2])
+		if !ok {
+			check.Report("rem_address", splits[2], errInvalidAddress)
+			continue
+		}
+		txQueue, rxQueue := asHexPair(check, "tx_queue:rx_queue", splits[4])
+		timerActive, timerExpires := asHexPair(check, "tr:tm->when", splits[5])
+
+		aSocket := Socket{
+			Sl:            check.Int("sl", strings.TrimSuffix(splits[0], ":")),
+			Local_address: local,
+			Rem_address:   remote,
+			St:            State(check.Uint64("st", splits[3], 16)),
+			Tx_queue:      txQueue,
+			Rx_queue:      rxQueue,
+			Tr:            int(timerActive),
+			Tm_when:       timerExpires,
+			Retrnsmt:      check.Uint64("retrnsmt", splits[6], 16),
+			Uid:           uint32(check.Uint64("uid", splits[7], 10)),
+			Timeout:       check.Uint64("timeout", splits[8], 10),
+			Inode:         check.Uint64("inode", splits[9], 10),
+		}
+		result = append(result, aSocket)
+	}
+	if err := scanner.Err(); err != nil {
+		return result, err
+	}
+	return result, check.Err()
+}
+
+func GetAsJson(name string) ([]byte, error) {
+	return GetAsJsonFrom(procfs.Default, name)
+}
+
+func GetAsJsonFrom(fsys fs.FS, name string) ([]byte, error) {
+	content, err := GetFrom(fsys, name)
+	if err != nil {
+		return []byte{}, err
+	}
+	return json.Marshal(content)
+}
+
+// Get values of /proc/net/tcp, or of another file in its format, as a map of
+// maps of interface{}, keyed by inode.  Sockets without an inode, such as
+// those in TIME_WAIT, are keyed by "sl" and their line, e.g. "sl4": several
+// sockets may share an address and port, e.g. listeners with SO_REUSEPORT.
+// Example:
+//     mySockets := tcp.GetAsMap(tcp.Tcp)
+//     x := mySockets["41523"]["st"]
+func GetAsMap(name string) (map[string]map[string]interface{}, error) {
+	return GetAsMapFrom(procfs.Default, name)
+}
+
+// Get values of the file name, e.g. Udp6, from the procfs tree fsys as a map
+// of maps of interface{}.
+func GetAsMapFrom(fsys fs.FS, name string) (map[string]map[string]interface{}, error) {
+	sockets, err := GetFrom(fsys, name)
+	if err != nil {
+		return make(map[string]map[string]interface{}), err
+	}
+	return socketsAsMap(sockets), nil
+}
+
+// Key of a socket in the map of GetAsMap.
+func mapKey(socket Socket) string {
+	if socket.Inode == 0 {
+		return "sl" + strconv.Itoa(socket.Sl)
+	}
+	return strconv.FormatUint(socket.Inode, 10)
+}
+
+// Sockets as a map of maps of interface{}, keyed by mapKey.
+func socketsAsMap(sockets []Socket) map[string]map[string]interface{} {
+
+	result := make(map[string]map[string]interface{})
+	for _, socket := range sockets {
+		result[mapKey(socket)] = map[string]interface{}{
+			"sl":            socket.Sl,
+			"local_address": socket.Local_address.String(),
+			"rem_address":   socket.Rem_address.String(),
+			"st":            socket.St.String(),
+			"tx_queue":      socket.Tx_queue,
+			"rx_queue":      socket.Rx_queue,
+			"tr":            socket.Tr,
+			"tm_when":       socket.Tm_when,
+			"retrnsmt":      socket.Retrnsmt,
+			"uid":           socket.Uid,
+			"timeout":       socket.Timeout,
+			"inode":         socket.Inode,
+		}
+	}
+	return result
+}
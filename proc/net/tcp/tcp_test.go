@@ -0,0 +1,145 @@
+package tcp
+
+import (
+	"encoding/binary"
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"io/fs"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// The trees under testdata/, read from an fs.FS and, through PROC_ROOT, from
+// the default procfs tree.
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+	}{
+		{"net_raw", Raw},
+		{"net_raw6", Raw6},
+		{"net_tcp", Tcp},
+		{"net_tcp6", Tcp6},
+		{"net_udp", Udp},
+		{"net_udp6", Udp6},
+	}
+	for _, tree := range golden.Trees(t) {
+		for _, test := range tests {
+			t.Run(tree.Name+"/"+test.name, func(t *testing.T) {
+				content, err := GetFrom(tree.FS, test.filename)
+				golden.Check(t, tree, test.name, content, err)
+			})
+			t.Run(tree.Name+"/PROC_ROOT/"+test.name, func(t *testing.T) {
+				tree.SetRoot(t)
+				content, err := Get(test.filename)
+				golden.Check(t, tree, test.name, content, err)
+			})
+		}
+	}
+}
+
+// Addresses, which the kernel writes in host byte order, and single values
+// of a socket.
+func TestGetFrom(t *testing.T) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		t.Skip("the sockets of testdata/ are of a little-endian host")
+	}
+	sockets, err := GetFrom(golden.Find(t, "linux-6.18-container-x86_64").FS, Tcp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sockets) != 6 {
+		t.Fatalf("got %d sockets, want 6", len(sockets))
+	}
+	tests := []struct {
+		socket        Socket
+		local, remote string
+		state         State
+		uid           uint32
+		inode         uint64
+	}{
+		{sockets[0], "0.0.0.0:2024", "0.0.0.0:0", Listen, 0, 662},
+		{sockets[1], "127.0.0.1:48271", "0.0.0.0:0", Listen, 65534, 908},
+		{sockets[2], "127.0.0.1:60690", "127.0.0.1:48271", Established, 0, 26538},
+	}
+	for _, test := range tests {
+		socket := test.socket
+		if socket.Local_address != netip.MustParseAddrPort(test.local) ||
+			socket.Rem_address != netip.MustParseAddrPort(test.remote) ||
+			socket.St != test.state || socket.Uid != test.uid || socket.Inode != test.inode {
+			t.Errorf("socket %d: got %+v, want %s %s %v uid %d inode %d", socket.Sl, socket, test.local, test.remote, test.state, test.uid, test.inode)
+		}
+	}
+
+	tree := fstest.MapFS{"net/tcp6": {Data: []byte(
+		"  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n" +
+			"   0: 00000000000000000000000001000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 4242 1 0000000000000000 100 0 0 10 0\n")}}
+	sockets, err = GetFrom(tree, Tcp6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sockets) != 1 || sockets[0].Local_address != netip.MustParseAddrPort("[::1]:8080") {
+		t.Errorf("tcp6: got %+v, want a socket at [::1]:8080", sockets)
+	}
+}
+
+// Malformed lines give a ParseError in strict mode and a warning in lenient
+// mode; a socket without its addresses is left out, the other sockets are
+// still read.
+func TestGetFromMalformed(t *testing.T) {
+	const header = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	const listen = "   1: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 662 1 0000000000000000 100 0 0 10 0\n"
+	expected := Socket{
+		Sl:            1,
+		Local_address: netip.MustParseAddrPort("0.0.0.0:8080"),
+		Rem_address:   netip.MustParseAddrPort("0.0.0.0:0"),
+		St:            Listen,
+		Inode:         662,
+	}
+	tests := []golden.Malformed{
+		{
+			Name:     "address",
+			Data:     header + "   0: 00000000:XYZ 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 661 1\n" + listen,
+			Expected: []Socket{expected},
+			Wants:    []procfs.ParseError{{File: "net/tcp", Line: 2, Field: "local_address", Text: "00000000:XYZ", Err: errInvalidAddress}},
+		},
+		{
+			Name:     "value",
+			Data:     header + strings.Replace(listen, "     0        0 662", "    -1        0 662", 1),
+			Expected: []Socket{expected},
+			Wants:    []procfs.ParseError{{File: "net/tcp", Line: 2, Field: "uid", Text: "-1", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "short line",
+			Data:     header + "   0: 00000000:1F90 00000000:0000 0A\n" + listen,
+			Expected: []Socket{expected},
+			Wants:    []procfs.ParseError{{File: "net/tcp", Line: 2, Text: "   0: 00000000:1F90 00000000:0000 0A", Err: procfs.ErrShortLine}},
+		},
+	}
+	for _, test := range tests {
+		golden.CheckMalformed(t, Tcp, test, func(fsys fs.FS) (interface{}, error) { return GetFrom(fsys, Tcp) })
+	}
+}
+
+// Parse any net/tcp without panicking, into a result or a ParseError.  The
+// other files share its format.
+func FuzzGetFrom(f *testing.F) {
+	golden.Seed(f, Tcp)
+	golden.Seed(f, Tcp6)
+	golden.Seed(f, Udp)
+	golden.Seed(f, Udp6)
+	golden.Seed(f, Raw)
+	golden.Seed(f, Raw6)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, fsys := range golden.Modes(fstest.MapFS{Tcp: {Data: data}}) {
+			_, err := GetFrom(fsys, Tcp)
+			golden.CheckError(t, err)
+			_, err = GetAsMapFrom(fsys, Tcp)
+			golden.CheckError(t, err)
+		}
+	})
+}
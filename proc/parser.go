@@ -0,0 +1,155 @@
+package proc
+
+import (
+	"io"
+	"io/fs"
+	"math"
+	"strconv"
+
+	"github.com/docktermj/go-proc-parse/proc/_pid_/stat"
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"github.com/docktermj/go-proc-parse/proc/meminfo"
+)
+
+// Size of the first buffer of a Parser.
+const parserBufferSize = 4096
+
+// Parses files of a procfs tree repeatedly, reusing its buffer and keeping
+// the files open between reads, so that polling does not allocate once every
+// file has been read.  It suits polling [pid]/stat of thousands of processes
+// every second.  A Parser is not safe for concurrent use; call Close when
+// done.
+// Example:
+//     myParser := proc.NewParser(proc.Default())
+//     defer myParser.Close()
+//     myStat := stat.Stat{}
+//     for range time.Tick(time.Second) {
+//         err := myParser.ParseStatInto(1, &myStat)
+//     }
+type Parser struct {
+	fsys    fs.FS
+	buffer  []byte
+	stats   map[int]readerAtFile
+	meminfo readerAtFile
+}
+
+// A file that can be read again from the start without reopening it, which
+// procfs files allow.
+type readerAtFile interface {
+	fs.File
+	io.ReaderAt
+}
+
+// A Parser of the procfs tree f.
+func NewParser(f FS) *Parser {
+	return &Parser{
+		fsys:   f,
+		buffer: make([]byte, parserBufferSize),
+		stats:  make(map[int]readerAtFile),
+	}
+}
+
+// Read file from the start into the buffer with a single read, growing the
+// buffer until the file fits.  Like procfs.ReadFile, the buffer grows up to
+// procfs.MaxReadSize, and the rest of a larger file is read after it.
+func (p *Parser) readAt(file readerAtFile) ([]byte, error) {
+	for {
+		n, err := file.ReadAt(p.buffer, 0)
+		if n == len(p.buffer) && n < procfs.MaxReadSize {
+			p.buffer = make([]byte, 2*len(p.buffer))
+			continue
+		}
+		result := p.buffer[:n]
+		if err == nil {
+			var rest []byte
+			rest, err = io.ReadAll(io.NewSectionReader(file, int64(n), math.MaxInt64-int64(n)))
+			result = append(result, rest...)
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		return result, nil
+	}
+}
+
+// Open name and read it.  The file is returned open for the next read if it
+// can be read at an offset, and nil if it had to be read whole.
+func (p *Parser) open(name string) ([]byte, readerAtFile, error) {
+	file, err := p.fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	readerAt, ok := file.(readerAtFile)
+	if !ok {
+		file.Close()
+		data, err := procfs.ReadFile(p.fsys, name)
+		return data, nil, err
+	}
+	data, err := p.readAt(readerAt)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return data, readerAt, nil
+}
+
+// Parse [pid]/stat into result, which may hold the values of an earlier
+// call.  If the read of an open file fails, it is opened again: a pid reused
+// by a new process reads as gone through the file of the old one.  The file
+// of a process that has exited is closed; see IsGone.
+func (p *Parser) ParseStatInto(pid int, result *stat.Stat) error {
+	if file, ok := p.stats[pid]; ok {
+		data, err := p.readAt(file)
+		if err == nil {
+			return stat.ParseInto(p.fsys, pid, data, result)
+		}
+		p.Forget(pid)
+	}
+	data, file, err := p.open(strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		p.stats[pid] = file
+	}
+	return stat.ParseInto(p.fsys, pid, data, result)
+}
+
+// Parse meminfo into result, which may hold the values of an earlier call.
+func (p *Parser) ParseMeminfoInto(result *meminfo.Meminfo) error {
+	if p.meminfo != nil {
+		data, err := p.readAt(p.meminfo)
+		if err == nil {
+			return meminfo.ParseInto(p.fsys, data, result)
+		}
+		p.meminfo.Close()
+		p.meminfo = nil
+	}
+	data, file, err := p.open("meminfo")
+	if err != nil {
+		return err
+	}
+	p.meminfo = file
+	return meminfo.ParseInto(p.fsys, data, result)
+}
+
+// Close the file of [pid]/stat, e.g. when pid is no longer polled.
+func (p *Parser) Forget(pid int) {
+	if file, ok := p.stats[pid]; ok {
+		file.Close()
+		delete(p.stats, pid)
+	}
+}
+
+// Close every open file.  The Parser may still be used; files are opened
+// again as needed.
+func (p *Parser) Close() error {
+	for pid := range p.stats {
+		p.Forget(pid)
+	}
+	if p.meminfo != nil {
+		p.meminfo.Close()
+		p.meminfo = nil
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package proc
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docktermj/go-proc-parse/proc/_pid_/stat"
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"github.com/docktermj/go-proc-parse/proc/meminfo"
+)
+
+// A procfs tree under testdata, so that every run parses the same files.
+const benchmarkTree = "../testdata/linux-6.18-container-x86_64"
+
+func benchmarkFS(b *testing.B) FS {
+	b.Helper()
+	f, err := NewFS(benchmarkTree)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return f
+}
+
+func BenchmarkParserParseStatInto(b *testing.B) {
+	parser := NewParser(benchmarkFS(b))
+	defer parser.Close()
+	aStat := stat.Stat{}
+	if err := parser.ParseStatInto(1, &aStat); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parser.ParseStatInto(1, &aStat)
+	}
+}
+
+// meminfo of benchmarkTree with a line of a newer kernel, which goes to Extra.
+func BenchmarkParserParseMeminfoInto(b *testing.B) {
+	data, err := os.ReadFile(filepath.Join(benchmarkTree, "meminfo"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	dir := b.TempDir()
+	data = append(data, "FutureLine:       1234 kB\n"...)
+	if err := os.WriteFile(filepath.Join(dir, "meminfo"), data, 0644); err != nil {
+		b.Fatal(err)
+	}
+	f, err := NewFS(dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	parser := NewParser(f)
+	defer parser.Close()
+	aMeminfo := meminfo.Meminfo{}
+	if err := parser.ParseMeminfoInto(&aMeminfo); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parser.ParseMeminfoInto(&aMeminfo)
+	}
+}
+
+// stat.GetFrom, for comparison with BenchmarkParserParseStatInto.
+func BenchmarkStatGet(b *testing.B) {
+	f := benchmarkFS(b)
+	if _, err := f.Stat(1); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Stat(1)
+	}
+}
+
+// A file of size bytes of "x" that is only generated as it is read.
+type generatedFile struct {
+	size int64
+}
+
+func (f generatedFile) ReadAt(buffer []byte, offset int64) (int, error) {
+	if offset >= f.size {
+		return 0, io.EOF
+	}
+	n := int(min(int64(len(buffer)), f.size-offset))
+	copy(buffer, bytes.Repeat([]byte("x"), n))
+	if n < len(buffer) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f generatedFile) Stat() (fs.FileInfo, error) { return nil, fs.ErrInvalid }
+func (f generatedFile) Read([]byte) (int, error)   { return 0, fs.ErrInvalid }
+func (f generatedFile) Close() error               { return nil }
+
+// The buffer grows until the file fits, up to procfs.MaxReadSize; a larger
+// file is still read whole.
+func TestParserReadAt(t *testing.T) {
+	tests := []struct {
+		size   int64
+		buffer int
+	}{
+		{100, parserBufferSize},
+		{parserBufferSize, 2 * parserBufferSize},
+		{10000, 4 * parserBufferSize},
+		{procfs.MaxReadSize + 5, procfs.MaxReadSize},
+	}
+	for _, test := range tests {
+		if test.size > procfs.MaxReadSize && testing.Short() {
+			continue
+		}
+		parser := NewParser(FS{})
+		data, err := parser.readAt(generatedFile{test.size})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(data)) != test.size || bytes.Count(data, []byte("x")) != len(data) {
+			t.Errorf("%d bytes: got %d bytes", test.size, len(data))
+		}
+		if len(parser.buffer) != test.buffer {
+			t.Errorf("%d bytes: got a buffer of %d, want %d", test.size, len(parser.buffer), test.buffer)
+		}
+	}
+}
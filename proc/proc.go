@@ -0,0 +1,230 @@
+package proc
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/docktermj/go-proc-parse/proc/_pid_/fd"
+	"github.com/docktermj/go-proc-parse/proc/_pid_/stat"
+	"github.com/docktermj/go-proc-parse/proc/_pid_/status"
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"github.com/docktermj/go-proc-parse/proc/loadavg"
+	"github.com/docktermj/go-proc-parse/proc/meminfo"
+	"github.com/docktermj/go-proc-parse/proc/net/dev"
+	"github.com/docktermj/go-proc-parse/proc/net/netstat"
+	"github.com/docktermj/go-proc-parse/proc/net/snmp"
+	"github.com/docktermj/go-proc-parse/proc/net/tcp"
+	sysstat "github.com/docktermj/go-proc-parse/proc/stat"
+	"github.com/docktermj/go-proc-parse/proc/uptime"
+)
+
+// Mount point of the proc filesystem on a running system.
+const DefaultMountPoint = procfs.DefaultMountPoint
+
+// A value in a procfs file that could not be parsed.  File is relative to the
+// procfs root and Line counts from 1.
+type ParseError = procfs.ParseError
+
+// ParseErrors collected by a lenient procfs tree.
+type Warnings = procfs.Warnings
+
+// Errors wrapped by a ParseError for malformed lines.
+var (
+	ErrShortLine      = procfs.ErrShortLine
+	ErrHeaderMismatch = procfs.ErrHeaderMismatch
+)
+
+// A procfs tree.  All parsers read their files relative to its root.
+// Example:
+//     myFS, err := proc.NewFS("/host/proc")
+//     myMeminfo, err := myFS.Meminfo()
+type FS struct {
+	fsys fs.FS
+}
+
+// The procfs tree at DefaultMountPoint.  Individual files may be redirected
+// by OS Environment variables such as PROC_MEMINFO; see the GetFilename
+// function of each package.
+func Default() FS {
+	return FS{fsys: procfs.Default}
+}
+
+// A procfs tree mounted at mountPoint, e.g. "/host/proc" inside a container.
+func NewFS(mountPoint string) (FS, error) {
+	info, err := os.Stat(mountPoint)
+	if err != nil {
+		return FS{}, err
+	}
+	if !info.IsDir() {
+		return FS{}, fmt.Errorf("proc: mount point %s is not a directory", mountPoint)
+	}
+	return FS{fsys: os.DirFS(mountPoint)}, nil
+}
+
+// A procfs tree backed by any fs.FS, e.g. an in-memory fstest.MapFS.
+func NewFSFromFS(fsys fs.FS) FS {
+	return FS{fsys: fsys}
+}
+
+// The same procfs tree in strict mode: parsers return a *ParseError for the
+// first value they cannot parse, along with what they could parse.
+// Example:
+//     myMeminfo, err := proc.Default().Strict().Meminfo()
+//     var parseError *proc.ParseError
+//     if errors.As(err, &parseError) {
+//         fmt.Println(parseError.File, parseError.Line, parseError.Field)
+//     }
+func (f FS) Strict() FS {
+	return FS{fsys: procfs.Strict(f.fsys)}
+}
+
+// The same procfs tree in lenient mode: parsers read what they cannot parse as
+// zero, as by default, and collect a *ParseError for each in warnings.
+// Example:
+//     myWarnings := &proc.Warnings{}
+//     myMeminfo, err := proc.Default().Lenient(myWarnings).Meminfo()
+//     for _, warning := range myWarnings.Errors() {
+//         log.Println(warning)
+//     }
+func (f FS) Lenient(warnings *Warnings) FS {
+	return FS{fsys: procfs.Lenient(f.fsys, warnings)}
+}
+
+// ParseMode lets the packages under proc see the mode of f when it is passed
+// to their GetFrom functions as an fs.FS.
+func (f FS) ParseMode() (bool, *Warnings) {
+	if mode, ok := f.fsys.(procfs.Mode); ok {
+		return mode.ParseMode()
+	}
+	return false, nil
+}
+
+// Open implements fs.FS.
+func (f FS) Open(name string) (fs.File, error) {
+	return f.fsys.Open(name)
+}
+
+// ReadLink implements fs.ReadLinkFS.  It fails if the underlying fs.FS does
+// not support symbolic links.
+func (f FS) ReadLink(name string) (string, error) {
+	return fs.ReadLink(f.fsys, name)
+}
+
+// Lstat implements fs.ReadLinkFS.
+func (f FS) Lstat(name string) (fs.FileInfo, error) {
+	return fs.Lstat(f.fsys, name)
+}
+
+// Process IDs in the procfs tree, in ascending order.
+func (f FS) Pids() ([]int, error) {
+	return procfs.Pids(f.fsys)
+}
+
+// Values of [pid]/stat for every process in the procfs tree.  Processes that
+// exit during the scan are left out.
+func (f FS) AllProcs() ([]stat.Stat, error) {
+	return stat.GetAllFrom(f.fsys)
+}
+
+func (f FS) Loadavg() (loadavg.Loadavg, error) {
+	return loadavg.GetFrom(f.fsys)
+}
+
+func (f FS) Meminfo() (meminfo.Meminfo, error) {
+	return meminfo.GetFrom(f.fsys)
+}
+
+// Memory usage of meminfo as reported by free(1).
+func (f FS) MeminfoSummary() (meminfo.Summary, error) {
+	return meminfo.GetSummaryFrom(f.fsys)
+}
+
+func (f FS) NetDev() (dev.Devs, error) {
+	return dev.GetFrom(f.fsys)
+}
+
+func (f FS) NetNetstat() (netstat.Netstat, error) {
+	return netstat.GetFrom(f.fsys)
+}
+
+func (f FS) NetSnmp() (snmp.Snmp, error) {
+	return snmp.GetFrom(f.fsys)
+}
+
+func (f FS) NetRaw() ([]tcp.Socket, error) {
+	return tcp.GetFrom(f.fsys, tcp.Raw)
+}
+
+func (f FS) NetRaw6() ([]tcp.Socket, error) {
+	return tcp.GetFrom(f.fsys, tcp.Raw6)
+}
+
+func (f FS) NetTcp() ([]tcp.Socket, error) {
+	return tcp.GetFrom(f.fsys, tcp.Tcp)
+}
+
+func (f FS) NetTcp6() ([]tcp.Socket, error) {
+	return tcp.GetFrom(f.fsys, tcp.Tcp6)
+}
+
+func (f FS) NetUdp() ([]tcp.Socket, error) {
+	return tcp.GetFrom(f.fsys, tcp.Udp)
+}
+
+func (f FS) NetUdp6() ([]tcp.Socket, error) {
+	return tcp.GetFrom(f.fsys, tcp.Udp6)
+}
+
+// System-wide values of stat, as opposed to Stat of a single process.
+func (f FS) SystemStat() (sysstat.Stat, error) {
+	return sysstat.GetFrom(f.fsys)
+}
+
+func (f FS) Uptime() (uptime.Uptime, error) {
+	return uptime.GetFrom(f.fsys)
+}
+
+// File descriptors of a process.  The procfs tree must support symbolic links,
+// as trees from NewFS and Default do.
+func (f FS) Fd(pid int) ([]fd.Fd, error) {
+	return fd.GetFrom(f.fsys, pid)
+}
+
+// Owners of every socket, pipe and anonymous inode in the procfs tree.
+// Example:
+//     myIndex, err := myFS.FdIndex()
+//     mySockets, err := myFS.NetTcp()
+//     x := myIndex.Sockets[mySockets[0].Inode]
+func (f FS) FdIndex() (fd.Index, error) {
+	return fd.GetIndexFrom(f.fsys)
+}
+
+func (f FS) Stat(pid int) (stat.Stat, error) {
+	return stat.GetFrom(f.fsys, pid)
+}
+
+func (f FS) Status(pid int) (status.Status, error) {
+	return status.GetFrom(f.fsys, pid)
+}
+
+// IsGone reports whether err, returned while reading a process's files, means
+// the process has exited.
+func IsGone(err error) bool {
+	return procfs.IsGone(err)
+}
+
+// Process IDs in the default procfs tree, in ascending order.
+func Pids() ([]int, error) {
+	return Default().Pids()
+}
+
+// Values of /proc/[pid]/stat for every process in the default procfs tree.
+// Example:
+//     myProcs, err := proc.AllProcs()
+//     for _, myProc := range myProcs {
+//         fmt.Println(myProc.Pid, myProc.Comm)
+//     }
+func AllProcs() ([]stat.Stat, error) {
+	return Default().AllProcs()
+}
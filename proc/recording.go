@@ -0,0 +1,289 @@
+package proc
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// Name of the directory of each snapshot in a recording: its time in UTC,
+// which sorts in time order.
+const SnapshotLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Leading bytes of archives compressed with gzip and zstd.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Writes snapshots of a procfs tree to a tar archive, each in a directory
+// named after its time with SnapshotLayout.  A snapshot holds the files that
+// the packages under proc read, for the tree and for every process, and the
+// links of [pid]/fd.  Once extracted, the directory of a snapshot is a
+// procfs tree that NewFS or PROC_ROOT can read.
+// Example:
+//     myRecorder := proc.NewRecorder(myFile, proc.Default())
+//     for range time.Tick(5 * time.Second) {
+//         err := myRecorder.Snapshot(time.Now())
+//     }
+//     err := myRecorder.Close()
+type Recorder struct {
+	fsys   FS
+	writer *tar.Writer
+	dirs   map[string]bool
+}
+
+// A Recorder writing snapshots of the procfs tree f to w.
+func NewRecorder(w io.Writer, f FS) *Recorder {
+	return &Recorder{
+		fsys:   f,
+		writer: tar.NewWriter(w),
+		dirs:   make(map[string]bool),
+	}
+}
+
+// Whether err, returned while reading a file for a snapshot, means the file
+// is left out: it does not exist, e.g. net/raw6 without IPv6, it belongs to a
+// process that has exited, or it may not be read, e.g. [pid]/fd of another
+// user.
+func isSkipped(err error) bool {
+	return IsGone(err) || errors.Is(err, fs.ErrPermission)
+}
+
+// Write the file name of the procfs tree into the snapshot in dir.
+func (r *Recorder) copyFile(dir string, modTime time.Time, name string) error {
+	data, err := procfs.ReadFile(r.fsys, name)
+	if isSkipped(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     dir + "/" + name,
+		Mode:     0444,
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+	}
+	if err := r.writer.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = r.writer.Write(data)
+	return err
+}
+
+// Write the links of the directory name, e.g. "1/fd", into the snapshot in
+// dir.
+func (r *Recorder) copyLinks(dir string, modTime time.Time, name string) error {
+	entries, err := fs.ReadDir(r.fsys, name)
+	if isSkipped(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		target, err := r.fsys.ReadLink(name + "/" + entry.Name())
+		if isSkipped(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{
+			Typeflag: tar.TypeSymlink,
+			Name:     dir + "/" + name + "/" + entry.Name(),
+			Linkname: target,
+			Mode:     0777,
+			ModTime:  modTime,
+		}
+		if err := r.writer.WriteHeader(header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write a snapshot of the procfs tree taken at t.  Files that cannot be read
+// are left out; see isSkipped.  Each snapshot must be taken at a different
+// time, so that it has a directory of its own.
+func (r *Recorder) Snapshot(t time.Time) error {
+	dir := t.UTC().Format(SnapshotLayout)
+	if r.dirs[dir] {
+		return fmt.Errorf("proc: recording already has a snapshot at %s", dir)
+	}
+	r.dirs[dir] = true
+	modTime := t.Truncate(time.Second) // tar rounds to seconds; the name of dir keeps the exact time.
+	files, processFiles := procfs.Files()
+
+	for _, name := range files {
+		if err := r.copyFile(dir, modTime, name); err != nil {
+			return err
+		}
+	}
+
+	pids, err := r.fsys.Pids()
+	if err != nil {
+		return err
+	}
+	for _, pid := range pids {
+		pidDir := strconv.Itoa(pid)
+		for _, name := range processFiles {
+			if err := r.copyFile(dir, modTime, pidDir+"/"+name); err != nil {
+				return err
+			}
+		}
+		if err := r.copyLinks(dir, modTime, pidDir+"/fd"); err != nil {
+			return err
+		}
+	}
+	return r.writer.Flush()
+}
+
+// Finish the archive.  It does not close the underlying writer.
+func (r *Recorder) Close() error {
+	return r.writer.Close()
+}
+
+// A procfs tree recorded at Time.
+type Snapshot struct {
+	Time time.Time
+	FS   FS
+}
+
+// Reads the snapshots written by a Recorder one at a time, in the order they
+// were written, so that only the snapshot being read is held in memory.  The
+// archive may be compressed with gzip.  The FS of each snapshot reads like
+// the tree it was taken from.
+// Example:
+//     myRecording, err := proc.NewRecordingReader(myFile)
+//     for {
+//         mySnapshot, err := myRecording.Next()
+//         if err == io.EOF {
+//             break
+//         }
+//         myMeminfo, err := mySnapshot.FS.Meminfo()
+//         fmt.Println(mySnapshot.Time, myMeminfo.MemFree)
+//     }
+type RecordingReader struct {
+	reader *tar.Reader
+	next   *recordedEntry
+	dirs   map[string]bool
+	err    error
+}
+
+// An entry of the archive, read ahead of the snapshot it starts.
+type recordedEntry struct {
+	dir    string
+	name   string
+	header *tar.Header
+	data   []byte
+}
+
+// A RecordingReader of the archive read from r.
+func NewRecordingReader(r io.Reader) (*RecordingReader, error) {
+
+	// Undo the compression, if any.
+
+	buffered := bufio.NewReader(r)
+	magic, _ := buffered.Peek(len(zstdMagic))
+	var archive io.Reader = buffered
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		archive = gzipReader
+	case bytes.HasPrefix(magic, zstdMagic):
+		return nil, errors.New("proc: recording is compressed with zstd; decompress it first, e.g. with \"zstd -d\"")
+	}
+	return &RecordingReader{reader: tar.NewReader(archive), dirs: make(map[string]bool)}, nil
+}
+
+// The next entry of the archive in the directory of a snapshot, or io.EOF.
+func (r *RecordingReader) nextEntry() (*recordedEntry, error) {
+	for {
+		header, err := r.reader.Next()
+		if err != nil {
+			return nil, err
+		}
+		dir, name, found := strings.Cut(header.Name, "/")
+		if !found || name == "" {
+			continue
+		}
+		result := &recordedEntry{dir: dir, name: name, header: header}
+		if header.Typeflag == tar.TypeReg {
+			result.data, err = io.ReadAll(r.reader)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+}
+
+// Read the next snapshot.  It returns io.EOF after the last one.  A
+// directory that holds a file twice, or appears again after another, holds
+// more than one snapshot, which is an error.
+func (r *RecordingReader) Next() (Snapshot, error) {
+	if r.err != nil {
+		return Snapshot{}, r.err
+	}
+	if r.next == nil {
+		r.next, r.err = r.nextEntry()
+		if r.err != nil {
+			return Snapshot{}, r.err
+		}
+	}
+
+	dir := r.next.dir
+	t, err := time.Parse(SnapshotLayout, dir)
+	if err != nil {
+		r.err = fmt.Errorf("proc: recording: %q is not the directory of a snapshot", dir)
+		return Snapshot{}, r.err
+	}
+	if r.dirs[dir] {
+		r.err = fmt.Errorf("proc: recording: more than one snapshot in %q", dir)
+		return Snapshot{}, r.err
+	}
+	r.dirs[dir] = true
+
+	// Add entries to the tree until the next snapshot starts.
+
+	tree := newMemFS()
+	for r.next.dir == dir {
+		header := r.next.header
+		if _, ok := tree.files[r.next.name]; ok {
+			r.err = fmt.Errorf("proc: recording: more than one snapshot in %q: %s appears twice", dir, r.next.name)
+			return Snapshot{}, r.err
+		}
+		switch header.Typeflag {
+		case tar.TypeReg:
+			tree.add(r.next.name, r.next.data, 0444, header.ModTime)
+		case tar.TypeSymlink:
+			tree.add(r.next.name, []byte(header.Linkname), fs.ModeSymlink|0777, header.ModTime)
+		}
+		r.next, err = r.nextEntry()
+		if err == io.EOF {
+			r.err = io.EOF
+			break
+		}
+		if err != nil {
+			r.err = err
+			return Snapshot{}, err
+		}
+	}
+	return Snapshot{Time: t, FS: NewFSFromFS(tree)}, nil
+}
@@ -0,0 +1,187 @@
+package proc
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// A copy of benchmarkTree with links in 1/fd, as a procfs tree.
+func recordedFS(t *testing.T) FS {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, os.DirFS(benchmarkTree)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "1", "fd"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, target := range map[string]string{"0": "/dev/null", "1": "pipe:[5678]", "3": "socket:[1234]"} {
+		if err := os.Symlink(target, filepath.Join(dir, "1", "fd", name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// Record snapshots of f at times.
+func record(t *testing.T, f FS, times ...time.Time) []byte {
+	t.Helper()
+	buffer := bytes.Buffer{}
+	recorder := NewRecorder(&buffer, f)
+	for _, aTime := range times {
+		if err := recorder.Snapshot(aTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	f := recordedFS(t)
+	times := []time.Time{
+		time.Date(2026, 10, 18, 12, 0, 0, 123456789, time.UTC),
+		time.Date(2026, 10, 18, 14, 0, 5, 0, time.FixedZone("CEST", 2*60*60)),
+	}
+	plain := record(t, f, times...)
+	compressed := bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(&compressed)
+	gzipWriter.Write(plain)
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, archive := range map[string][]byte{"plain": plain, "gzip": compressed.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			reader, err := NewRecordingReader(bytes.NewReader(archive))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, aTime := range times {
+				snapshot, err := reader.Next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !snapshot.Time.Equal(aTime) {
+					t.Errorf("Time = %v; want %v", snapshot.Time, aTime)
+				}
+				for _, name := range SchemaNames() {
+					expected, expectedErr := f.Get(name, 1)
+					actual, err := snapshot.FS.Get(name, 1)
+					if errors.Is(err, fs.ErrNotExist) != errors.Is(expectedErr, fs.ErrNotExist) || !reflect.DeepEqual(actual, expected) {
+						t.Errorf("%s: %+v, %v; want %+v, %v", name, actual, err, expected, expectedErr)
+					}
+				}
+			}
+			for count := 0; count < 2; count++ {
+				if _, err := reader.Next(); err != io.EOF {
+					t.Errorf("Next after the last snapshot: %v; want io.EOF", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordingZstd(t *testing.T) {
+	_, err := NewRecordingReader(bytes.NewReader(append(zstdMagic, 0, 0, 0, 0)))
+	if err == nil || !strings.Contains(err.Error(), "zstd") {
+		t.Errorf("NewRecordingReader of zstd: %v", err)
+	}
+}
+
+// Two snapshots in one directory are rejected, whether written by a Recorder
+// or not.
+func TestRecordingDuplicateDirectory(t *testing.T) {
+	f := recordedFS(t)
+	aTime := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	recorder := NewRecorder(io.Discard, f)
+	if err := recorder.Snapshot(aTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Snapshot(aTime.In(time.FixedZone("CEST", 2*60*60))); err == nil {
+		t.Error("Snapshot at the time of the previous one: no error")
+	}
+
+	dir := aTime.Format(SnapshotLayout)
+	otherDir := aTime.Add(time.Second).Format(SnapshotLayout)
+	tests := map[string][]string{
+		"consecutive": {dir + "/meminfo", dir + "/uptime", dir + "/meminfo"},
+		"apart":       {dir + "/meminfo", otherDir + "/meminfo", dir + "/uptime"},
+	}
+	for name, entries := range tests {
+		t.Run(name, func(t *testing.T) {
+			buffer := bytes.Buffer{}
+			writer := tar.NewWriter(&buffer)
+			for _, entry := range entries {
+				writer.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: entry, Mode: 0444})
+			}
+			writer.Close()
+			reader, err := NewRecordingReader(&buffer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for {
+				_, err := reader.Next()
+				if err == io.EOF {
+					t.Fatal("no error")
+				}
+				if err != nil {
+					break
+				}
+			}
+		})
+	}
+}
+
+// A memFS passes fstest.TestFS when its symlinks resolve within it.  Links
+// such as those of [pid]/fd do not: they exist for ReadLink and Lstat, but
+// not for Open.
+func TestMemFS(t *testing.T) {
+	modTime := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tree := newMemFS()
+	tree.add("meminfo", []byte("MemTotal: 1 kB\n"), 0444, modTime)
+	tree.add("1/stat", []byte("1 (init) S\n"), 0444, modTime)
+	tree.add("1/cwd", []byte(".."), fs.ModeSymlink|0777, modTime)
+	tree.add("self", []byte("1"), fs.ModeSymlink|0777, modTime)
+	tree.add("1/fd/3", []byte("../stat"), fs.ModeSymlink|0777, modTime)
+	if err := fstest.TestFS(tree, "meminfo", "1/stat", "1/cwd", "self", "1/fd/3"); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, target := range map[string]string{
+		"1/fd/0": "/dev/null",
+		"1/fd/4": "socket:[1234]",
+		"loop":   "loop",
+	} {
+		tree.add(name, []byte(target), fs.ModeSymlink|0777, modTime)
+		if _, err := tree.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Open(%q) = %v; want fs.ErrNotExist", name, err)
+		}
+		if link, err := tree.ReadLink(name); link != target || err != nil {
+			t.Errorf("ReadLink(%q) = %q, %v; want %q", name, link, err, target)
+		}
+		if info, err := tree.Lstat(name); err != nil || info.Mode().Type() != fs.ModeSymlink {
+			t.Errorf("Lstat(%q) = %v, %v; want a symlink", name, info, err)
+		}
+	}
+	if err := fstest.TestFS(tree, "meminfo"); err == nil {
+		t.Error("fstest.TestFS passes with links out of the tree")
+	}
+}
@@ -0,0 +1,180 @@
+package proc
+
+import (
+	"context"
+	"time"
+
+	"github.com/docktermj/go-proc-parse/proc/_pid_/stat"
+	sysstat "github.com/docktermj/go-proc-parse/proc/stat"
+)
+
+// Values of [pid]/stat for a set of processes, and the CPU time of the whole
+// system from stat, read at about the same Time.
+type Sample struct {
+	Time  time.Time
+	Cpu   sysstat.CPU
+	Cpus  int
+	Procs map[int]stat.Stat
+}
+
+// Per-second rates of a process between two samples.  CPUPercent is the
+// share of one CPU, as in top, so a process busy on two CPUs is at 200.
+// RSSDelta is the change of the resident set size in bytes.
+type Rate struct {
+	Pid        int     `json:"pid"`
+	Comm       string  `json:"comm"`
+	CPUPercent float64 `json:"cpu_percent"`
+	Minflt     float64 `json:"minflt"`
+	Majflt     float64 `json:"majflt"`
+	RSSDelta   int64   `json:"rss_delta"`
+}
+
+type Rates map[int]Rate
+
+// Take a sample of pids, or of every process if pids is empty.  Processes
+// that have exited are left out.
+func (f FS) Sample(pids []int) (Sample, error) {
+
+	result := Sample{
+		Procs: make(map[int]stat.Stat),
+	}
+
+	systemStat, err := f.SystemStat()
+	if err != nil {
+		return result, err
+	}
+	result.Time = time.Now()
+	result.Cpu = systemStat.Cpu
+	result.Cpus = len(systemStat.Cpus)
+
+	if len(pids) == 0 {
+		pids, err = f.Pids()
+		if err != nil {
+			return result, err
+		}
+	}
+	for _, pid := range pids {
+		aStat, err := f.Stat(pid)
+		if IsGone(err) {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Procs[pid] = aStat
+	}
+	return result, nil
+}
+
+// Compute per-second rates of each process between two samples.  Processes
+// present in only one of the samples are left out, as are pids that were
+// reused by a new process in between, which is detected by a different
+// Starttime.
+// Example:
+//     prev, _ := proc.Default().Sample(nil)
+//     time.Sleep(2 * time.Second)
+//     cur, _ := proc.Default().Sample(nil)
+//     x := proc.Delta(prev, cur)[1].CPUPercent
+func Delta(prev Sample, cur Sample) Rates {
+
+	result := Rates{}
+	seconds := cur.Time.Sub(prev.Time).Seconds()
+	if seconds <= 0 {
+		return result
+	}
+
+	// Measure elapsed time in clock ticks of one CPU from /proc/stat, which
+	// counts in the same unit as [pid]/stat.  Fall back to the wall clock.
+
+	cpuTicks := seconds * float64(stat.ClockTicks)
+	if cur.Cpus > 0 && cur.Cpu.Total() > prev.Cpu.Total() {
+		cpuTicks = float64(cur.Cpu.Total()-prev.Cpu.Total()) / float64(cur.Cpus)
+	}
+
+	for pid, curStat := range cur.Procs {
+		prevStat, ok := prev.Procs[pid]
+		if !ok || prevStat.Starttime != curStat.Starttime {
+			continue
+		}
+
+		aRate := Rate{
+			Pid:      pid,
+			Comm:     curStat.Comm,
+			Minflt:   float64(counterDelta(prevStat.Minflt, curStat.Minflt)) / seconds,
+			Majflt:   float64(counterDelta(prevStat.Majflt, curStat.Majflt)) / seconds,
+			RSSDelta: int64(curStat.RSSBytes()) - int64(prevStat.RSSBytes()),
+		}
+		ticks := counterDelta(prevStat.Utime+prevStat.Stime, curStat.Utime+curStat.Stime)
+		aRate.CPUPercent = float64(ticks) / cpuTicks * 100
+		result[pid] = aRate
+	}
+	return result
+}
+
+// Counters of a process only grow; guard against a torn read.
+func counterDelta(prev uint64, cur uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+// Samples a set of processes repeatedly.  A Sampler is not safe for
+// concurrent use.
+// Example:
+//     mySampler := proc.NewSampler(proc.Default(), nil)
+//     err := mySampler.Run(ctx, 2*time.Second, func(myRates proc.Rates) {
+//         fmt.Println(myRates[1].CPUPercent)
+//     })
+type Sampler struct {
+	fs     FS
+	pids   []int
+	prev   Sample
+	primed bool
+}
+
+// A Sampler of pids in fs, or of every process if pids is empty.
+func NewSampler(fs FS, pids []int) *Sampler {
+	return &Sampler{
+		fs:   fs,
+		pids: pids,
+	}
+}
+
+// Take a sample and return the rates since the previous one.  The first call
+// returns no rates.
+func (s *Sampler) Next() (Rates, error) {
+	cur, err := s.fs.Sample(s.pids)
+	if err != nil {
+		return Rates{}, err
+	}
+	result := Rates{}
+	if s.primed {
+		result = Delta(s.prev, cur)
+	}
+	s.prev = cur
+	s.primed = true
+	return result, nil
+}
+
+// Call handle with the rates of every interval until ctx is done or a sample
+// fails.
+func (s *Sampler) Run(ctx context.Context, interval time.Duration, handle func(Rates)) error {
+	if _, err := s.Next(); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			rates, err := s.Next()
+			if err != nil {
+				return err
+			}
+			handle(rates)
+		}
+	}
+}
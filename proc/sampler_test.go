@@ -0,0 +1,189 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/docktermj/go-proc-parse/proc/_pid_/stat"
+)
+
+// A line of [pid]/stat with the fields that Delta reads.
+type pidStat struct {
+	pid, starttime               int
+	utime, stime, minflt, majflt uint64
+	rss                          int64
+}
+
+func (p pidStat) String() string {
+	fields := strings.Fields("S 0 0 0 0 -1 4194560 0 0 0 0 0 0 0 0 20 0 1 0 0 24416256 0 18446744073709551615 1 1 0 0 0 0 0 4096 1088 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0")
+	set := func(field int, value interface{}) { fields[field-3] = fmt.Sprint(value) } // Fields of man 5 proc, from 3.
+	set(10, p.minflt)
+	set(12, p.majflt)
+	set(14, p.utime)
+	set(15, p.stime)
+	set(22, p.starttime)
+	set(24, p.rss)
+	return fmt.Sprintf("%d (p%d) %s\n", p.pid, p.pid, strings.Join(fields, " "))
+}
+
+// A procfs tree of two CPUs with cpuTotal ticks in stat, and procs.
+func samplerTree(cpuTotal uint64, procs ...pidStat) fstest.MapFS {
+	cpu := func(name string, total uint64) string {
+		return fmt.Sprintf("%s %d 0 %d %d 0 0 0 0 0 0\n", name, total/4, total/4, total/2)
+	}
+	result := fstest.MapFS{
+		"stat": {Data: []byte(cpu("cpu ", cpuTotal) + cpu("cpu0", cpuTotal/2) + cpu("cpu1", cpuTotal/2) + "btime 1792309001\n")},
+	}
+	for _, aProc := range procs {
+		result[fmt.Sprintf("%d/stat", aProc.pid)] = &fstest.MapFile{Data: []byte(aProc.String())}
+	}
+	return result
+}
+
+// Two samples: 1 runs on, 2 exits, the pid 3 is reused by a new process and
+// 4 starts.  Between them the CPUs count 400 ticks, 200 each.
+var (
+	beforeTree = samplerTree(10000,
+		pidStat{pid: 1, starttime: 7, utime: 60, stime: 40, minflt: 1000, majflt: 10, rss: 2350},
+		pidStat{pid: 2, starttime: 8, utime: 5},
+		pidStat{pid: 3, starttime: 500, utime: 5},
+	)
+	afterTree = samplerTree(10400,
+		pidStat{pid: 1, starttime: 7, utime: 90, stime: 60, minflt: 1600, majflt: 14, rss: 2300},
+		pidStat{pid: 3, starttime: 900, utime: 1},
+		pidStat{pid: 4, starttime: 950, utime: 1},
+	)
+)
+
+// Set the units of [pid]/stat for a test.
+func setUnits(t *testing.T, clockTicks uint64, pageSize uint64) {
+	savedClockTicks, savedPageSize := stat.ClockTicks, stat.PageSize
+	stat.ClockTicks, stat.PageSize = clockTicks, pageSize
+	t.Cleanup(func() { stat.ClockTicks, stat.PageSize = savedClockTicks, savedPageSize })
+}
+
+func TestSample(t *testing.T) {
+	sample, err := NewFSFromFS(afterTree).Sample(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.Cpus != 2 || sample.Cpu.Total() != 10400 || sample.Time.IsZero() {
+		t.Errorf("Sample: %d CPUs, %d ticks at %v", sample.Cpus, sample.Cpu.Total(), sample.Time)
+	}
+	if len(sample.Procs) != 3 || sample.Procs[4].Starttime != 950 {
+		t.Errorf("Sample(nil).Procs = %+v", sample.Procs)
+	}
+
+	// 2 has exited.
+
+	sample, err = NewFSFromFS(afterTree).Sample([]int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sample.Procs[2]; ok || len(sample.Procs) != 1 {
+		t.Errorf("Sample([1 2]).Procs = %+v", sample.Procs)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	setUnits(t, 100, 4096)
+	prev, err := NewFSFromFS(beforeTree).Sample(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur, err := NewFSFromFS(afterTree).Sample(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur.Time = prev.Time.Add(2 * time.Second)
+
+	// 50 of the 200 ticks of one CPU, 600 minor and 4 major faults in 2
+	// seconds, and 50 pages less.
+
+	expected := Rates{
+		1: {Pid: 1, Comm: "p1", CPUPercent: 25, Minflt: 300, Majflt: 2, RSSDelta: -50 * 4096},
+	}
+	if actual := Delta(prev, cur); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Delta = %+v; want %+v", actual, expected)
+	}
+
+	// Without CPU ticks in stat, the 2 seconds are 200 ticks of the clock.
+
+	withoutCpus := cur
+	withoutCpus.Cpus = 0
+	if actual := Delta(prev, withoutCpus)[1].CPUPercent; actual != 25 {
+		t.Errorf("CPUPercent without CPU ticks = %g; want 25", actual)
+	}
+	setUnits(t, 1000, 4096)
+	if actual := Delta(prev, withoutCpus)[1].CPUPercent; actual != 2.5 {
+		t.Errorf("CPUPercent without CPU ticks at 1000 Hz = %g; want 2.5", actual)
+	}
+
+	for _, elapsed := range []time.Duration{0, -time.Second} {
+		cur.Time = prev.Time.Add(elapsed)
+		if actual := Delta(prev, cur); len(actual) != 0 {
+			t.Errorf("Delta over %v = %+v; want no rates", elapsed, actual)
+		}
+	}
+}
+
+// A procfs tree that moves on to the next of trees each time stat, the first
+// file of a Sample, is opened.  It starts at index -1.
+type sequenceFS struct {
+	trees []fs.FS
+	index int
+}
+
+func (s *sequenceFS) Open(name string) (fs.File, error) {
+	if name == "stat" && s.index+1 < len(s.trees) {
+		s.index++
+	}
+	return s.trees[s.index].Open(name)
+}
+
+func TestSamplerNext(t *testing.T) {
+	setUnits(t, 100, 4096)
+	sampler := NewSampler(NewFSFromFS(&sequenceFS{trees: []fs.FS{beforeTree, afterTree}, index: -1}), nil)
+	rates, err := sampler.Next()
+	if err != nil || len(rates) != 0 {
+		t.Fatalf("first Next = %+v, %v; want no rates", rates, err)
+	}
+	rates, err = sampler.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 1 || rates[1].CPUPercent != 25 || rates[1].RSSDelta != -50*4096 {
+		t.Errorf("second Next = %+v", rates)
+	}
+}
+
+func TestSamplerRun(t *testing.T) {
+	setUnits(t, 100, 4096)
+	sampler := NewSampler(NewFSFromFS(&sequenceFS{trees: []fs.FS{beforeTree, afterTree}, index: -1}), []int{1, 2, 3})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handled := []Rates{}
+	err := sampler.Run(ctx, time.Millisecond, func(rates Rates) {
+		handled = append(handled, rates)
+		cancel()
+	})
+	if err != context.Canceled {
+		t.Errorf("Run = %v; want %v", err, context.Canceled)
+	}
+	if len(handled) != 1 || len(handled[0]) != 1 || handled[0][1].CPUPercent != 25 {
+		t.Errorf("Run handled %+v", handled)
+	}
+
+	// A sample that fails ends Run.
+
+	sampler = NewSampler(NewFSFromFS(fstest.MapFS{}), nil)
+	if err := sampler.Run(context.Background(), time.Millisecond, func(Rates) {}); err == nil {
+		t.Error("Run without stat: no error")
+	}
+}
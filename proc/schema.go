@@ -0,0 +1,161 @@
+package proc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/docktermj/go-proc-parse/proc/_pid_/status"
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"github.com/docktermj/go-proc-parse/proc/net/tcp"
+)
+
+// Version of the JSON written by the GetAsJson functions, and of the schemas
+// under schema/.  It changes when a key is renamed or removed or the type of
+// its value changes; adding a key does not change it.
+const SchemaVersion = 1
+
+// Base of the $id of the published schemas.
+const schemaBaseURL = "https://github.com/docktermj/go-proc-parse/schema"
+
+// Naming of the keys of struct fields in JSON.
+type KeyCase = procfs.KeyCase
+
+const (
+	KernelCase = procfs.KernelCase // As named by the kernel, e.g. "Active_anon", "ReceiveBytes".
+	SnakeCase  = procfs.SnakeCase  // e.g. "active_anon", "receive_bytes".
+	CamelCase  = procfs.CamelCase  // e.g. "activeAnon", "receiveBytes".
+)
+
+// ParseKeyCase parses "kernel", "snake" or "camel", e.g. from a flag.
+func ParseKeyCase(name string) (KeyCase, error) {
+	return procfs.ParseKeyCase(name)
+}
+
+// MarshalJSON writes v, a value returned by one of the packages under proc,
+// like json.Marshal, with the keys of struct fields in the case keys.  Keys of
+// maps, such as the names of network interfaces, are left as they are.
+// Example:
+//     myMeminfo, err := proc.Default().Meminfo()
+//     x, err := proc.MarshalJSON(myMeminfo, proc.SnakeCase) // {"mem_total":...}
+func MarshalJSON(v interface{}, keys KeyCase) ([]byte, error) {
+	return procfs.MarshalJSON(v, keys)
+}
+
+// Schemas of types that write their own JSON.
+var marshalerSchemas = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(tcp.State(0)): {
+		"type":        "string",
+		"description": "State by name, e.g. \"LISTEN\".",
+	},
+	reflect.TypeOf(status.Capabilities(0)): {
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "Names of the capabilities in the set, e.g. \"CAP_CHOWN\".",
+	},
+}
+
+// Names of the schemas returned by Schema, in alphabetical order.
+func SchemaNames() []string {
+	result := []string{}
+	for _, aFile := range files {
+		result = append(result, aFile.name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Schema returns the JSON Schema of the JSON of a package, e.g. "net_dev",
+// with keys in the case keys.  It is generated from the Go types, so it
+// cannot fall behind them.
+// Example:
+//     x, err := proc.Schema("meminfo", proc.KernelCase)
+func Schema(name string, keys KeyCase) ([]byte, error) {
+	aFile, ok := findFile(name)
+	if !ok {
+		return nil, fmt.Errorf("proc: unknown schema %q", name)
+	}
+	result, err := typeSchema(aFile.valueType, keys)
+	if err != nil {
+		return nil, err
+	}
+	result["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	result["$id"] = fmt.Sprintf("%s/v%d/%s/%s.schema.json", schemaBaseURL, SchemaVersion, keys, name)
+	result["title"] = name
+	result["description"] = fmt.Sprintf("%s  Schema version %d, %s case keys.", aFile.description, SchemaVersion, keys)
+	return json.MarshalIndent(result, "", "  ")
+}
+
+// Schema of the JSON that encoding/json writes for values of t.  Objects
+// allow additional properties, so that adding a key is compatible.  It is an
+// error for two fields of a struct to have the same key in the case keys.
+func typeSchema(t reflect.Type, keys KeyCase) (map[string]interface{}, error) {
+	if result, ok := marshalerSchemas[t]; ok {
+		return result, nil
+	}
+	if procfs.IsMarshaler(t) {
+		return map[string]interface{}{"type": "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), keys)
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(t.Elem(), keys)
+		return map[string]interface{}{
+			"type":  []string{"array", "null"},
+			"items": items,
+		}, err
+	case reflect.Map:
+		values, err := typeSchema(t.Elem(), keys)
+		result := map[string]interface{}{
+			"type":                 []string{"object", "null"},
+			"additionalProperties": values,
+		}
+		switch t.Key().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			result["propertyNames"] = map[string]interface{}{"pattern": "^-?[0-9]+$"}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			result["propertyNames"] = map[string]interface{}{"pattern": "^[0-9]+$"}
+		}
+		return result, err
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+		for index := 0; index < t.NumField(); index++ {
+			key, omitEmpty, ok := procfs.FieldKey(t.Field(index))
+			if !ok {
+				continue
+			}
+			key = keys.Key(key)
+			if _, ok := properties[key]; ok {
+				return nil, fmt.Errorf("proc: %s has two fields with the %s case key %q", t, keys, key)
+			}
+			property, err := typeSchema(t.Field(index).Type, keys)
+			if err != nil {
+				return nil, err
+			}
+			properties[key] = property
+			if !omitEmpty {
+				required = append(required, key)
+			}
+		}
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		}, nil
+	}
+	return map[string]interface{}{}, nil
+}
@@ -0,0 +1,239 @@
+package proc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// Every golden file under testdata/ is valid under its committed schema in
+// schema/, e.g. golden/net_dev.json under schema/v1/kernel/net_dev.schema.json.
+func TestGoldenMatchesSchema(t *testing.T) {
+	trees := golden.Trees(t)
+	root := filepath.Dir(filepath.Dir(trees[0].Dir))
+	for _, tree := range trees {
+		goldens, err := filepath.Glob(filepath.Join(tree.Dir, "golden", "*.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range goldens {
+			name := strings.TrimSuffix(filepath.Base(path), ".json")
+			t.Run(tree.Name+"/"+name, func(t *testing.T) {
+				schemaPath := filepath.Join(root, "schema", fmt.Sprintf("v%d", SchemaVersion), KernelCase.String(), name+".schema.json")
+				schema := map[string]interface{}{}
+				readJSON(t, schemaPath, &schema)
+				var value interface{}
+				readJSON(t, path, &value)
+				for _, problem := range validate(schema, value, "") {
+					t.Errorf("%s: %s", path, problem)
+				}
+			})
+		}
+	}
+}
+
+// What the parsers read from every tree under testdata/ is valid, with keys in
+// snake and camel case, under the committed schema of the case.
+func TestOutputMatchesSchema(t *testing.T) {
+	trees := golden.Trees(t)
+	root := filepath.Dir(filepath.Dir(trees[0].Dir))
+	for _, tree := range trees {
+		f := NewFSFromFS(tree.FS)
+		for _, name := range SchemaNames() {
+			content, err := f.Get(name, tree.Pid)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: %s: %v", tree.Name, name, err)
+			}
+			for _, keys := range []KeyCase{SnakeCase, CamelCase} {
+				t.Run(fmt.Sprintf("%s/%s/%s", tree.Name, keys, name), func(t *testing.T) {
+					schemaPath := filepath.Join(root, "schema", fmt.Sprintf("v%d", SchemaVersion), keys.String(), name+".schema.json")
+					schema := map[string]interface{}{}
+					readJSON(t, schemaPath, &schema)
+					data, err := MarshalJSON(content, keys)
+					if err != nil {
+						t.Fatal(err)
+					}
+					var value interface{}
+					if err := decodeJSON(data, &value); err != nil {
+						t.Fatal(err)
+					}
+					for _, problem := range validate(schema, value, "") {
+						t.Error(problem)
+					}
+				})
+			}
+		}
+	}
+}
+
+// The schemas under schema/ are the ones Schema generates, as written by
+// "go-proc-parse schema".
+func TestSchemaMatchesCommitted(t *testing.T) {
+	root := filepath.Dir(filepath.Dir(golden.Trees(t)[0].Dir))
+	for _, keys := range []KeyCase{KernelCase, SnakeCase, CamelCase} {
+		for _, name := range SchemaNames() {
+			path := filepath.Join(root, "schema", fmt.Sprintf("v%d", SchemaVersion), keys.String(), name+".schema.json")
+			expected, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := Schema(name, keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(append(actual, '\n'), expected) {
+				t.Errorf("%s differs from Schema(%q, %s); run: go-proc-parse schema --dir schema", path, name, keys)
+			}
+		}
+	}
+}
+
+func TestSchemaCollidingKeys(t *testing.T) {
+	type colliding struct {
+		FirstKey  int `json:"FirstKey"`
+		SecondKey int `json:"first_key"`
+	}
+	if _, err := typeSchema(reflect.TypeOf(colliding{}), KernelCase); err != nil {
+		t.Errorf("KernelCase: %v", err)
+	}
+	for _, keys := range []KeyCase{SnakeCase, CamelCase} {
+		if _, err := typeSchema(reflect.TypeOf([]colliding{}), keys); err == nil {
+			t.Errorf("%s: no error for two fields with the key %q", keys, keys.Key("first_key"))
+		}
+	}
+}
+
+// Decode the JSON file path into v, keeping numbers as json.Number.
+func readJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decodeJSON(data, v); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+}
+
+// Decode data into v, keeping numbers as json.Number.
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// The ways value, at path, breaks schema.  Only the keywords that Schema
+// writes are known; any other is reported, so that the test cannot pass by
+// ignoring it.
+func validate(schema map[string]interface{}, value interface{}, path string) []string {
+	result := []string{}
+	fail := func(format string, args ...interface{}) {
+		result = append(result, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	keywords := []string{}
+	for keyword := range schema {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	object, isObject := value.(map[string]interface{})
+	for _, keyword := range keywords {
+		argument := schema[keyword]
+		switch keyword {
+		case "$schema", "$id", "title", "description":
+		case "type":
+			types := []interface{}{argument}
+			if list, ok := argument.([]interface{}); ok {
+				types = list
+			}
+			matched := false
+			for _, aType := range types {
+				matched = matched || hasType(value, aType.(string))
+			}
+			if !matched {
+				fail("%v is not of type %v", value, argument)
+			}
+		case "minimum":
+			number, ok := value.(json.Number)
+			if !ok {
+				continue
+			}
+			actual, _, err := big.ParseFloat(number.String(), 10, 128, big.ToNearestEven)
+			minimum, _, err2 := big.ParseFloat(argument.(json.Number).String(), 10, 128, big.ToNearestEven)
+			if err != nil || err2 != nil || actual.Cmp(minimum) < 0 {
+				fail("%v is less than %v", value, argument)
+			}
+		case "pattern":
+			text, ok := value.(string)
+			if ok && !regexp.MustCompile(argument.(string)).MatchString(text) {
+				fail("%q does not match %s", text, argument)
+			}
+		case "required":
+			for _, key := range argument.([]interface{}) {
+				if _, ok := object[key.(string)]; isObject && !ok {
+					fail("%q is missing", key)
+				}
+			}
+		case "properties":
+			for key, property := range argument.(map[string]interface{}) {
+				if propertyValue, ok := object[key]; isObject && ok {
+					result = append(result, validate(property.(map[string]interface{}), propertyValue, path+"/"+key)...)
+				}
+			}
+		case "additionalProperties":
+			properties, _ := schema["properties"].(map[string]interface{})
+			for key, propertyValue := range object {
+				if _, ok := properties[key]; !ok {
+					result = append(result, validate(argument.(map[string]interface{}), propertyValue, path+"/"+key)...)
+				}
+			}
+		case "propertyNames":
+			for key := range object {
+				result = append(result, validate(argument.(map[string]interface{}), key, path+"/"+key)...)
+			}
+		case "items":
+			items, _ := value.([]interface{})
+			for index, item := range items {
+				result = append(result, validate(argument.(map[string]interface{}), item, fmt.Sprintf("%s/%d", path, index))...)
+			}
+		default:
+			fail("unknown keyword %q", keyword)
+		}
+	}
+	return result
+}
+
+// Whether value, decoded with json.Number, is of the JSON Schema type aType.
+func hasType(value interface{}, aType string) bool {
+	switch value := value.(type) {
+	case nil:
+		return aType == "null"
+	case bool:
+		return aType == "boolean"
+	case string:
+		return aType == "string"
+	case json.Number:
+		return aType == "number" || aType == "integer" && !strings.ContainsAny(value.String(), ".eE")
+	case []interface{}:
+		return aType == "array"
+	case map[string]interface{}:
+		return aType == "object"
+	}
+	return false
+}
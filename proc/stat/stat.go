@@ -0,0 +1,244 @@
+package stat
+
+import (
+	"encoding/json"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// Time spent by a CPU, or all CPUs together, in each mode, measured in clock
+// ticks (USER_HZ).  Guest and Guest_nice are already included in User and
+// Nice.
+type CPU struct {
+	User       uint64 `json:"user"`
+	Nice       uint64 `json:"nice"`
+	System     uint64 `json:"system"`
+	Idle       uint64 `json:"idle"`
+	Iowait     uint64 `json:"iowait"`
+	Irq        uint64 `json:"irq"`
+	Softirq    uint64 `json:"softirq"`
+	Steal      uint64 `json:"steal"`
+	Guest      uint64 `json:"guest"`
+	Guest_nice uint64 `json:"guest_nice"`
+}
+
+// Total time spent by the CPU in all modes, in clock ticks.
+func (c CPU) Total() uint64 {
+	return c.User + c.Nice + c.System + c.Idle + c.Iowait + c.Irq + c.Softirq + c.Steal
+}
+
+// References:
+// - Linux command: man 5 proc
+// - http://man7.org/linux/man-pages/man5/proc.5.html  "/proc/stat"
+type Stat struct {
+	Cpu           CPU         `json:"cpu"`
+	Cpus          map[int]CPU `json:"cpus"`
+	Intr          uint64      `json:"intr"`
+	Intr_per_irq  []uint64    `json:"intr_per_irq"`
+	Ctxt          uint64      `json:"ctxt"`
+	Btime         uint64      `json:"btime"`
+	Processes     uint64      `json:"processes"`
+	Procs_running uint64      `json:"procs_running"`
+	Procs_blocked uint64      `json:"procs_blocked"`
+	Softirq       uint64      `json:"softirq"`
+	Softirq_types []uint64    `json:"softirq_types"`
+}
+
+// Path of the file relative to the procfs root.
+const filename = "stat"
+
+// Allow filename to be specified by OS Environment variable: PROC_STAT
+func GetFilename() string {
+	return procfs.Filename(filename)
+}
+
+// Fields not written by older kernels are empty.  They are left as zero
+// rather than reported.
+func asUint64(check *procfs.Checker, field string, value string) uint64 {
+	if value == "" {
+		return uint64(0)
+	}
+	return check.Uint64(field, value, 10)
+}
+
+func asUint64s(check *procfs.Checker, field string, values []string) []uint64 {
+	result := make([]uint64, len(values))
+	for index, value := range values {
+		result[index] = asUint64(check, field, value)
+	}
+	return result
+}
+
+// A cpu line after its key, e.g. "cpu0".  Older kernels write fewer columns;
+// the missing ones are zero.  A ParseError names the column, e.g. "iowait".
+func asCPU(check *procfs.Checker, values []string) CPU {
+	for len(values) < 10 {
+		values = append(values, "")
+	}
+	return CPU{
+		User:       asUint64(check, "user", values[0]),
+		Nice:       asUint64(check, "nice", values[1]),
+		System:     asUint64(check, "system", values[2]),
+		Idle:       asUint64(check, "idle", values[3]),
+		Iowait:     asUint64(check, "iowait", values[4]),
+		Irq:        asUint64(check, "irq", values[5]),
+		Softirq:    asUint64(check, "softirq", values[6]),
+		Steal:      asUint64(check, "steal", values[7]),
+		Guest:      asUint64(check, "guest", values[8]),
+		Guest_nice: asUint64(check, "guest_nice", values[9]),
+	}
+}
+
+// Get values of /proc/stat.
+// Example:
+//     myStat, err := stat.Get()
+//     x := myStat.Cpu.Idle
+func Get() (Stat, error) {
+	return GetFrom(procfs.Default)
+}
+
+// Get values of stat from the procfs tree fsys.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     myStat, err := stat.GetFrom(myFS)
+func GetFrom(fsys fs.FS) (Stat, error) {
+
+	result := Stat{
+		Cpus: make(map[int]CPU),
+	}
+
+	// Read the file in a single read.
+
+	data, err := procfs.ReadFile(fsys, filename)
+	if err != nil {
+		return result, err
+	}
+
+	// Parse the file.
+
+	check := procfs.NewChecker(fsys, filename)
+	scanner := procfs.NewScanner(data)
+	for scanner.Scan() {
+		check.NextLine()
+		inputLine := scanner.Text()
+		splits := strings.Fields(inputLine)
+		if len(splits) == 0 || !check.Fields(inputLine, splits, 2) {
+			continue
+		}
+		key := splits[0]
+		values := splits[1:]
+
+		switch {
+		case key == "cpu":
+			result.Cpu = asCPU(check, values)
+		case strings.HasPrefix(key, "cpu"):
+			cpu, err := strconv.Atoi(strings.TrimPrefix(key, "cpu"))
+			if err != nil {
+				check.Report("", inputLine, err)
+				continue
+			}
+			result.Cpus[cpu] = asCPU(check, values)
+		case key == "intr":
+			result.Intr = asUint64(check, key, values[0])
+			result.Intr_per_irq = asUint64s(check, key, values[1:])
+		case key == "ctxt":
+			result.Ctxt = asUint64(check, key, values[0])
+		case key == "btime":
+			result.Btime = asUint64(check, key, values[0])
+		case key == "processes":
+			result.Processes = asUint64(check, key, values[0])
+		case key == "procs_running":
+			result.Procs_running = asUint64(check, key, values[0])
+		case key == "procs_blocked":
+			result.Procs_blocked = asUint64(check, key, values[0])
+		case key == "softirq":
+			result.Softirq = asUint64(check, key, values[0])
+			result.Softirq_types = asUint64s(check, key, values[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, err
+	}
+	return result, check.Err()
+}
+
+func GetAsJson() ([]byte, error) {
+	return GetAsJsonFrom(procfs.Default)
+}
+
+func GetAsJsonFrom(fsys fs.FS) ([]byte, error) {
+	content, err := GetFrom(fsys)
+	if err != nil {
+		return []byte{}, err
+	}
+	return json.Marshal(content)
+}
+
+// Get values of /proc/stat as JSON with the keys of struct fields in the case
+// keys.  Keys of maps are left as they are.
+// Example:
+//     myJson, err := sysstat.GetAsJsonKeys(proc.CamelCase) // {"cpu":{"guestNice":...}}
+func GetAsJsonKeys(keys procfs.KeyCase) ([]byte, error) {
+	return GetAsJsonKeysFrom(procfs.Default, keys)
+}
+
+func GetAsJsonKeysFrom(fsys fs.FS, keys procfs.KeyCase) ([]byte, error) {
+	content, err := GetFrom(fsys)
+	if err != nil {
+		return []byte{}, err
+	}
+	return procfs.MarshalJSON(content, keys)
+}
+
+func cpuAsMap(cpu CPU) map[string]uint64 {
+	result := make(map[string]uint64)
+	result["user"] = cpu.User
+	result["nice"] = cpu.Nice
+	result["system"] = cpu.System
+	result["idle"] = cpu.Idle
+	result["iowait"] = cpu.Iowait
+	result["irq"] = cpu.Irq
+	result["softirq"] = cpu.Softirq
+	result["steal"] = cpu.Steal
+	result["guest"] = cpu.Guest
+	result["guest_nice"] = cpu.Guest_nice
+	return result
+}
+
+// Get values of /proc/stat as a map of interface{}.  CPU lines are maps of
+// uint64 keyed by mode.
+// Example:
+//     myStat := stat.GetAsMap()
+//     x := myStat["cpu0"].(map[string]uint64)["idle"]
+func GetAsMap() (map[string]interface{}, error) {
+	return GetAsMapFrom(procfs.Default)
+}
+
+// Get values of stat from the procfs tree fsys as a map of interface{}.
+func GetAsMapFrom(fsys fs.FS) (map[string]interface{}, error) {
+
+	result := make(map[string]interface{})
+	stat, err := GetFrom(fsys)
+	if err != nil {
+		return result, err
+	}
+
+	result["cpu"] = cpuAsMap(stat.Cpu)
+	for cpu, times := range stat.Cpus {
+		result["cpu"+strconv.Itoa(cpu)] = cpuAsMap(times)
+	}
+	result["intr"] = stat.Intr
+	result["intr_per_irq"] = stat.Intr_per_irq
+	result["ctxt"] = stat.Ctxt
+	result["btime"] = stat.Btime
+	result["processes"] = stat.Processes
+	result["procs_running"] = stat.Procs_running
+	result["procs_blocked"] = stat.Procs_blocked
+	result["softirq"] = stat.Softirq
+	result["softirq_types"] = stat.Softirq_types
+
+	return result, nil
+}
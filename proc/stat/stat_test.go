@@ -0,0 +1,98 @@
+package stat
+
+import (
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"io/fs"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// The trees under testdata/, read from an fs.FS and, through PROC_ROOT, from
+// the default procfs tree.
+func TestGolden(t *testing.T) {
+	for _, tree := range golden.Trees(t) {
+		t.Run(tree.Name, func(t *testing.T) {
+			content, err := GetFrom(tree.FS)
+			golden.Check(t, tree, "stat", content, err)
+		})
+		t.Run(tree.Name+"/PROC_ROOT", func(t *testing.T) {
+			tree.SetRoot(t)
+			content, err := Get()
+			golden.Check(t, tree, "stat", content, err)
+		})
+	}
+}
+
+// The total and per-CPU lines, and single values after them.
+func TestGetFrom(t *testing.T) {
+	stat, err := GetFrom(golden.Find(t, "linux-6.18-container-x86_64").FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := CPU{User: 31816, System: 7361, Idle: 267723, Iowait: 322, Softirq: 7, Steal: 2803}
+	if stat.Cpu != expected || len(stat.Cpus) != 1 || stat.Cpus[0] != expected {
+		t.Errorf("got cpu %+v, cpus %+v, want %+v for both", stat.Cpu, stat.Cpus, expected)
+	}
+	if stat.Intr != 559472 || stat.Ctxt != 1303352 || stat.Btime != 1792309001 || stat.Processes != 20849 || stat.Procs_running != 1 {
+		t.Errorf("got intr %d, ctxt %d, btime %d, processes %d, procs_running %d", stat.Intr, stat.Ctxt, stat.Btime, stat.Processes, stat.Procs_running)
+	}
+	if len(stat.Intr_per_irq) == 0 || stat.Intr_per_irq[25] != 1 {
+		t.Errorf("intr_per_irq: got %v", stat.Intr_per_irq)
+	}
+}
+
+// Malformed lines give a ParseError in strict mode and a warning in lenient
+// mode; the other values are still read.
+func TestGetFromMalformed(t *testing.T) {
+	tests := []golden.Malformed{
+		{
+			Name:     "cpu value",
+			Data:     "cpu0 1 2 3 4 x 6\nctxt 7\n",
+			Expected: Stat{Cpus: map[int]CPU{0: {User: 1, Nice: 2, System: 3, Idle: 4, Irq: 6}}, Ctxt: 7},
+			Wants:    []procfs.ParseError{{File: "stat", Line: 1, Field: "iowait", Text: "x", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "total cpu value",
+			Data:     "cpu  1 2 3 4 5 6 7 8 9 -10\ncpu0 1 2 3 4 5 6 7 8 9 10\n",
+			Expected: Stat{Cpu: CPU{1, 2, 3, 4, 5, 6, 7, 8, 9, 0}, Cpus: map[int]CPU{0: {1, 2, 3, 4, 5, 6, 7, 8, 9, 10}}},
+			Wants:    []procfs.ParseError{{File: "stat", Line: 1, Field: "guest_nice", Text: "-10", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "cpu number",
+			Data:     "cpux 1 2 3 4\nctxt 7\n",
+			Expected: Stat{Cpus: map[int]CPU{}, Ctxt: 7},
+			Wants:    []procfs.ParseError{{File: "stat", Line: 1, Text: "cpux 1 2 3 4", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "value",
+			Data:     "intr 10 1 x 3\nbtime 1792309001\n",
+			Expected: Stat{Cpus: map[int]CPU{}, Intr: 10, Intr_per_irq: []uint64{1, 0, 3}, Btime: 1792309001},
+			Wants:    []procfs.ParseError{{File: "stat", Line: 1, Field: "intr", Text: "x", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "short line",
+			Data:     "ctxt\nbtime 1792309001\n",
+			Expected: Stat{Cpus: map[int]CPU{}, Btime: 1792309001},
+			Wants:    []procfs.ParseError{{File: "stat", Line: 1, Text: "ctxt", Err: procfs.ErrShortLine}},
+		},
+	}
+	for _, test := range tests {
+		golden.CheckMalformed(t, "stat", test, func(fsys fs.FS) (interface{}, error) { return GetFrom(fsys) })
+	}
+}
+
+// Parse any stat without panicking, into a result or a ParseError.
+func FuzzGetFrom(f *testing.F) {
+	golden.Seed(f, "stat")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, fsys := range golden.Modes(fstest.MapFS{"stat": {Data: data}}) {
+			_, err := GetFrom(fsys)
+			golden.CheckError(t, err)
+			_, err = GetAsMapFrom(fsys)
+			golden.CheckError(t, err)
+		}
+	})
+}
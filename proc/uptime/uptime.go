@@ -0,0 +1,136 @@
+package uptime
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+)
+
+// Path of the file relative to the procfs root.
+const filename = "uptime"
+
+// Time since boot, and time spent idle summed over all CPUs, so Idle may
+// exceed Uptime on a multi-processor system.  JSON encodes both in
+// nanoseconds.
+// References:
+// - http://man7.org/linux/man-pages/man5/proc.5.html  "/proc/uptime"
+type Uptime struct {
+	Uptime time.Duration `json:"uptime"`
+	Idle   time.Duration `json:"idle"`
+}
+
+// Allow filename to be specified by OS Environment variable: PROC_UPTIME
+func GetFilename() string {
+	return procfs.Filename(filename)
+}
+
+// The kernel writes seconds with two decimals, e.g. "1601.21".
+var errSeconds = errors.New("invalid number of seconds")
+
+// Parse seconds such as "1601.21" without the rounding of a float64.  Idle
+// may not fit a time.Duration on a long-running host with many CPUs, so it is
+// reported as out of range rather than wrapping around.
+func asDuration(check *procfs.Checker, field string, value string) time.Duration {
+	seconds, hundredths, ok := strings.Cut(value, ".")
+	if !ok || len(seconds) == 0 || len(hundredths) != 2 {
+		check.Report(field, value, errSeconds)
+		return time.Duration(0)
+	}
+	whole, err := strconv.ParseUint(seconds, 10, 64)
+	if err == nil && whole > uint64(math.MaxInt64/time.Second)-1 {
+		err = strconv.ErrRange
+	}
+	if err != nil {
+		check.Report(field, value, err)
+		return time.Duration(0)
+	}
+	fraction, err := strconv.ParseUint(hundredths, 10, 8)
+	if err != nil {
+		check.Report(field, value, errSeconds)
+		return time.Duration(0)
+	}
+	return time.Duration(whole)*time.Second + time.Duration(fraction)*10*time.Millisecond
+}
+
+// Get values of /proc/uptime.
+// Example:
+//     myUptime, err := uptime.Get()
+//     x := myUptime.Uptime.Hours()
+func Get() (Uptime, error) {
+	return GetFrom(procfs.Default)
+}
+
+// Get values of uptime from the procfs tree fsys.
+// Example:
+//     myFS := os.DirFS("/host/proc")
+//     myUptime, err := uptime.GetFrom(myFS)
+func GetFrom(fsys fs.FS) (Uptime, error) {
+
+	result := Uptime{}
+
+	// Read the file in a single read.
+
+	data, err := procfs.ReadFile(fsys, filename)
+	if err != nil {
+		return result, err
+	}
+
+	// Parse the file, e.g. "1601.21 1401.00".
+
+	check := procfs.NewChecker(fsys, filename)
+	scanner := procfs.NewScanner(data)
+	if !scanner.Scan() {
+		return result, scanner.Err()
+	}
+	check.NextLine()
+	inputLine := scanner.Text()
+	splits := strings.Fields(inputLine)
+	if !check.Fields(inputLine, splits, 2) {
+		return result, check.Err()
+	}
+
+	result.Uptime = asDuration(check, "uptime", splits[0])
+	result.Idle = asDuration(check, "idle", splits[1])
+	return result, check.Err()
+}
+
+func GetAsJson() ([]byte, error) {
+	return GetAsJsonFrom(procfs.Default)
+}
+
+func GetAsJsonFrom(fsys fs.FS) ([]byte, error) {
+	content, err := GetFrom(fsys)
+	if err != nil {
+		return []byte{}, err
+	}
+	return json.Marshal(content)
+}
+
+// Get values of /proc/uptime as a map of seconds.
+// Example:
+//     myUptime := uptime.GetAsMap()
+//     x := myUptime["uptime"]
+func GetAsMap() (map[string]float64, error) {
+	return GetAsMapFrom(procfs.Default)
+}
+
+// Get values of uptime from the procfs tree fsys as a map of seconds.
+func GetAsMapFrom(fsys fs.FS) (map[string]float64, error) {
+
+	result := make(map[string]float64)
+	uptime, err := GetFrom(fsys)
+	if err != nil {
+		return result, err
+	}
+
+	result["uptime"] = uptime.Uptime.Seconds()
+	result["idle"] = uptime.Idle.Seconds()
+
+	return result, nil
+}
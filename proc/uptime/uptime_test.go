@@ -0,0 +1,84 @@
+package uptime
+
+import (
+	"github.com/docktermj/go-proc-parse/proc/internal/procfs"
+	"io/fs"
+	"strconv"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/docktermj/go-proc-parse/proc/internal/golden"
+)
+
+// The trees under testdata/, read from an fs.FS and, through PROC_ROOT, from
+// the default procfs tree.
+func TestGolden(t *testing.T) {
+	for _, tree := range golden.Trees(t) {
+		t.Run(tree.Name, func(t *testing.T) {
+			content, err := GetFrom(tree.FS)
+			golden.Check(t, tree, "uptime", content, err)
+		})
+		t.Run(tree.Name+"/PROC_ROOT", func(t *testing.T) {
+			tree.SetRoot(t)
+			content, err := Get()
+			golden.Check(t, tree, "uptime", content, err)
+		})
+	}
+}
+
+// The seconds of uptime, with their hundredths, as exact durations.
+func TestGetFrom(t *testing.T) {
+	uptime, err := GetFrom(golden.Find(t, "linux-6.18-container-x86_64").FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Uptime{
+		Uptime: 3086*time.Second + 430*time.Millisecond,
+		Idle:   2677*time.Second + 230*time.Millisecond,
+	}
+	if uptime != expected {
+		t.Errorf("got %+v, want %+v", uptime, expected)
+	}
+}
+
+// A malformed line gives a ParseError in strict mode and a warning in lenient
+// mode; the other field is still read.
+func TestGetFromMalformed(t *testing.T) {
+	tests := []golden.Malformed{
+		{
+			Name:     "no hundredths",
+			Data:     "3086.43 2677\n",
+			Expected: Uptime{Uptime: 3086*time.Second + 430*time.Millisecond},
+			Wants:    []procfs.ParseError{{File: "uptime", Line: 1, Field: "idle", Text: "2677", Err: errSeconds}},
+		},
+		{
+			Name:     "value",
+			Data:     "30x6.43 2677.23\n",
+			Expected: Uptime{Idle: 2677*time.Second + 230*time.Millisecond},
+			Wants:    []procfs.ParseError{{File: "uptime", Line: 1, Field: "uptime", Text: "30x6.43", Err: strconv.ErrSyntax}},
+		},
+		{
+			Name:     "short line",
+			Data:     "3086.43\n",
+			Expected: Uptime{},
+			Wants:    []procfs.ParseError{{File: "uptime", Line: 1, Text: "3086.43", Err: procfs.ErrShortLine}},
+		},
+	}
+	for _, test := range tests {
+		golden.CheckMalformed(t, "uptime", test, func(fsys fs.FS) (interface{}, error) { return GetFrom(fsys) })
+	}
+}
+
+// Parse any uptime without panicking, into a result or a ParseError.
+func FuzzGetFrom(f *testing.F) {
+	golden.Seed(f, "uptime")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, fsys := range golden.Modes(fstest.MapFS{"uptime": {Data: data}}) {
+			_, err := GetFrom(fsys)
+			golden.CheckError(t, err)
+			_, err = GetAsMapFrom(fsys)
+			golden.CheckError(t, err)
+		}
+	})
+}